@@ -0,0 +1,311 @@
+package set
+
+import (
+	"encoding/binary"
+	"github.com/dgryski/go-farm"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+const (
+	// sparsePrecision is the precision of the sparse representation,
+	// which counts almost exactly until it is converted to registers.
+	sparsePrecision = 25
+	sparseRankBits  = 6
+
+	approxCounterVersion = 1
+)
+
+// ApproxCounter estimates the number of distinct strings it was given,
+// using HyperLogLog++ over 64 bits hashes. With a precision of p, it
+// holds 2^p one byte registers and has a standard error of about
+// 1.04/sqrt(2^p). Small cardinalities are kept in a sparse list of
+// higher precision instead, until it grows as large as the registers.
+//
+// Once converted, the estimator is the one of Ertl's "New cardinality
+// estimation algorithms for HyperLogLog sketches", which replaces the
+// empirical bias correction tables of HyperLogLog++.
+type ApproxCounter struct {
+	p    uint8
+	fh64 func([]byte) uint64
+
+	sparse []uint32 // sorted entries, an index at sparsePrecision and its rank
+	tmp    []uint32 // entries not merged in sparse yet
+	dense  []uint8  // registers, nil while sparse
+}
+
+// NewApproxCounter creates an ApproxCounter of precision p, from 4 to 18,
+// using a 64 bits hasher func.
+func NewApproxCounter(p uint8, fh64 func([]byte) uint64) *ApproxCounter {
+	if p < 4 || p > 18 {
+		panic("set: approx counter precision must be from 4 to 18")
+	}
+	return &ApproxCounter{p: p, fh64: fh64}
+}
+
+// NewFarmApproxCounter is an ApproxCounter with farmhash for hasher.
+func NewFarmApproxCounter(p uint8) *ApproxCounter { return NewApproxCounter(p, farm.Hash64) }
+
+// rank is the position of the first 1 bit in w, counting from 1, or
+// max if w is all zeros.
+func rank(w uint64, max uint8) uint8 {
+	if w == 0 {
+		return max
+	}
+	return uint8(bits.LeadingZeros64(w)) + 1
+}
+
+func (c *ApproxCounter) m() int { return 1 << c.p }
+
+// Add a string to the count.
+func (c *ApproxCounter) Add(s string) {
+	h := c.fh64([]byte(s))
+	if c.dense != nil {
+		c.addDense(h)
+		return
+	}
+	idx := uint32(h >> (64 - sparsePrecision))
+	r := rank(h<<sparsePrecision, 64-sparsePrecision+1)
+	c.tmp = append(c.tmp, idx<<sparseRankBits|uint32(r))
+	if len(c.tmp) >= c.m()/4 {
+		c.mergeSparse()
+	}
+}
+
+func (c *ApproxCounter) addDense(h uint64) {
+	idx := h >> (64 - c.p)
+	r := rank(h<<c.p, 64-c.p+1)
+	if r > c.dense[idx] {
+		c.dense[idx] = r
+	}
+}
+
+// mergeSparse folds the pending entries in the sparse list, keeping the
+// highest rank of each index, and switches to registers once the list
+// takes more room than they would.
+func (c *ApproxCounter) mergeSparse() {
+	if len(c.tmp) == 0 {
+		return
+	}
+	sort.Sort(uint32Slice(c.tmp))
+	merged := make([]uint32, 0, len(c.sparse)+len(c.tmp))
+	a, b := c.sparse, c.tmp
+	for len(a) != 0 || len(b) != 0 {
+		var e uint32
+		if len(b) == 0 || (len(a) != 0 && a[0] < b[0]) {
+			e, a = a[0], a[1:]
+		} else {
+			e, b = b[0], b[1:]
+		}
+		// entries sort by index then rank, the last of an index wins
+		if n := len(merged); n != 0 && merged[n-1]>>sparseRankBits == e>>sparseRankBits {
+			merged[n-1] = e
+		} else {
+			merged = append(merged, e)
+		}
+	}
+	c.sparse, c.tmp = merged, c.tmp[:0]
+
+	if 4*len(c.sparse) > c.m() {
+		c.toDense()
+	}
+}
+
+// toDense converts the sparse entries to registers.
+func (c *ApproxCounter) toDense() {
+	c.dense = make([]uint8, c.m())
+	for _, e := range c.sparse {
+		c.addSparseEntry(e)
+	}
+	for _, e := range c.tmp {
+		c.addSparseEntry(e)
+	}
+	c.sparse, c.tmp = nil, nil
+}
+
+func (c *ApproxCounter) addSparseEntry(e uint32) {
+	extra := sparsePrecision - c.p
+	idx := e >> sparseRankBits
+	r := uint8(e & (1<<sparseRankBits - 1))
+	// the index bits dropped by the lower precision come first in the
+	// part of the hash the register ranks
+	if low := idx & (1<<extra - 1); low != 0 {
+		r = extra - uint8(bits.Len32(low)) + 1
+	} else {
+		r += extra
+	}
+	if reg := idx >> extra; r > c.dense[reg] {
+		c.dense[reg] = r
+	}
+}
+
+type uint32Slice []uint32
+
+func (p uint32Slice) Len() int           { return len(p) }
+func (p uint32Slice) Less(i, j int) bool { return p[i] < p[j] }
+func (p uint32Slice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// Estimate the number of distinct strings added to this counter.
+func (c *ApproxCounter) Estimate() uint64 {
+	if c.dense == nil {
+		c.mergeSparse()
+	}
+	if c.dense == nil {
+		// linear counting over the sparse indices
+		m := float64(uint64(1) << sparsePrecision)
+		return uint64(math.Round(m * math.Log(m/(m-float64(len(c.sparse))))))
+	}
+
+	q := 64 - int(c.p)
+	hist := make([]float64, q+2)
+	for _, r := range c.dense {
+		hist[r]++
+	}
+	m := float64(c.m())
+	z := m * hllTau(1-hist[q+1]/m)
+	for k := q; k >= 1; k-- {
+		z = 0.5 * (z + hist[k])
+	}
+	z += m * hllSigma(hist[0]/m)
+	return uint64(math.Round(m * m / (2 * math.Ln2) / z))
+}
+
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		prev := z
+		z += x * y
+		y += y
+		if z == prev {
+			return z
+		}
+	}
+}
+
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		prev := z
+		y *= 0.5
+		z -= (1 - x) * (1 - x) * y
+		if z == prev {
+			return z / 3
+		}
+	}
+}
+
+// Merge the counts of other into this counter, as if it had been given
+// the strings given to other. Both must have the same precision and
+// hasher.
+func (c *ApproxCounter) Merge(other *ApproxCounter) error {
+	if c.p != other.p {
+		return ErrIncompatible
+	}
+	if c.dense == nil && other.dense == nil {
+		c.tmp = append(c.tmp, other.sparse...)
+		c.tmp = append(c.tmp, other.tmp...)
+		c.mergeSparse()
+		return nil
+	}
+	if c.dense == nil {
+		c.toDense()
+	}
+	regs := other.dense
+	if regs == nil {
+		cp := &ApproxCounter{p: other.p, sparse: other.sparse, tmp: other.tmp}
+		cp.toDense()
+		regs = cp.dense
+	}
+	for i, r := range regs {
+		if r > c.dense[i] {
+			c.dense[i] = r
+		}
+	}
+	return nil
+}
+
+// MarshalBinary encodes the counter: a version, the precision, then
+// either the sparse entries delta encoded as varints, or the registers.
+func (c *ApproxCounter) MarshalBinary() ([]byte, error) {
+	if c.dense == nil {
+		c.mergeSparse()
+	}
+	if c.dense != nil {
+		buf := []byte{approxCounterVersion, c.p, 1}
+		return append(buf, c.dense...), nil
+	}
+	buf := []byte{approxCounterVersion, c.p, 0}
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64(len(c.sparse)))
+	buf = append(buf, tmp[:n]...)
+	prev := uint32(0)
+	for _, e := range c.sparse {
+		n = binary.PutUvarint(tmp[:], uint64(e-prev))
+		buf = append(buf, tmp[:n]...)
+		prev = e
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a counter encoded by MarshalBinary. The
+// counter keeps its hasher, which must be the one of the encoded counter.
+func (c *ApproxCounter) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 || data[0] != approxCounterVersion || data[1] < 4 || data[1] > 18 {
+		return ErrMalformed
+	}
+	p := data[1]
+	switch data[2] {
+	case 1:
+		if len(data) != 3+1<<p {
+			return ErrMalformed
+		}
+		for _, r := range data[3:] {
+			if r > 64-p+1 {
+				return ErrMalformed
+			}
+		}
+		c.p, c.sparse, c.tmp = p, nil, nil
+		c.dense = append([]uint8(nil), data[3:]...)
+		return nil
+	case 0:
+		data = data[3:]
+		count, n := binary.Uvarint(data)
+		if n <= 0 || count > uint64(len(data)) {
+			return ErrMalformed
+		}
+		data = data[n:]
+		sparse := make([]uint32, count)
+		prev := uint64(0)
+		for i := range sparse {
+			delta, n := binary.Uvarint(data)
+			if n <= 0 || prev+delta > math.MaxUint32 {
+				return ErrMalformed
+			}
+			data = data[n:]
+			prev += delta
+			sparse[i] = uint32(prev)
+			// an index at sparsePrecision and a rank of its hash, the
+			// indexes increasing
+			idx, r := sparse[i]>>sparseRankBits, sparse[i]&(1<<sparseRankBits-1)
+			if idx >= 1<<sparsePrecision || r < 1 || r > 64-sparsePrecision+1 ||
+				i > 0 && idx == sparse[i-1]>>sparseRankBits {
+				return ErrMalformed
+			}
+		}
+		if len(data) != 0 {
+			return ErrMalformed
+		}
+		c.p, c.sparse, c.tmp, c.dense = p, sparse, nil, nil
+		return nil
+	}
+	return ErrMalformed
+}
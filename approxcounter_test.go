@@ -0,0 +1,99 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func checkEstimate(t *testing.T, c *set.ApproxCounter, n int, p uint8) {
+	got := float64(c.Estimate())
+	// 4 standard errors, and a key of slack for tiny counts
+	tolerance := 4*1.04/math.Sqrt(float64(uint(1)<<p))*float64(n) + 1
+	if math.Abs(got-float64(n)) > tolerance {
+		t.Errorf("p=%d: estimated %.0f distinct strings, want %d±%.0f", p, got, n, tolerance)
+	}
+}
+
+func TestApproxCounter_Estimate(t *testing.T) {
+	for _, p := range []uint8{10, 14} {
+		for _, n := range []int{0, 1, 10, 1000, 100000} {
+			c := set.NewFarmApproxCounter(p)
+			for i := 0; i < n; i++ {
+				// duplicates must not count
+				c.Add(strconv.Itoa(i))
+				c.Add(strconv.Itoa(i))
+			}
+			checkEstimate(t, c, n, p)
+		}
+	}
+}
+
+func TestApproxCounter_Merge(t *testing.T) {
+	const p = 12
+	for _, n := range []int{100, 100000} {
+		a, b := set.NewFarmApproxCounter(p), set.NewFarmApproxCounter(p)
+		for i := 0; i < n; i++ {
+			a.Add(strconv.Itoa(i))
+		}
+		// b overlaps half of a
+		for i := n / 2; i < n+n/2; i++ {
+			b.Add(strconv.Itoa(i))
+		}
+		if err := a.Merge(b); err != nil {
+			t.Fatal(err)
+		}
+		checkEstimate(t, a, n+n/2, p)
+	}
+
+	if err := set.NewFarmApproxCounter(10).Merge(set.NewFarmApproxCounter(12)); err != set.ErrIncompatible {
+		t.Errorf("want %v, got %v", set.ErrIncompatible, err)
+	}
+}
+
+func TestApproxCounter_MarshalBinary(t *testing.T) {
+	const p = 12
+	for _, n := range []int{0, 100, 100000} {
+		c := set.NewFarmApproxCounter(p)
+		for i := 0; i < n; i++ {
+			c.Add(strconv.Itoa(i))
+		}
+		data, err := c.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded := set.NewFarmApproxCounter(p)
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Estimate() != c.Estimate() {
+			t.Errorf("decoded estimate %d, want %d", decoded.Estimate(), c.Estimate())
+		}
+
+		if err := decoded.UnmarshalBinary(data[:len(data)-1]); n != 0 && err != set.ErrMalformed {
+			t.Errorf("want %v on truncated input, got %v", set.ErrMalformed, err)
+		}
+	}
+}
+
+func TestApproxCounter_UnmarshalBadInput(t *testing.T) {
+	c := set.NewFarmApproxCounter(12)
+	for _, bad := range [][]byte{
+		{},
+		{1, 12},
+		{2, 12, 0, 0}, // unknown version
+		{1, 3, 0, 0},  // precision too low
+		{1, 12, 2, 0}, // unknown representation
+		{1, 12, 0, 1, 0x81, 0x80, 0x80, 0x80, 0x08},                // index past the sparse precision
+		{1, 12, 0, 1, 0x40},                                        // rank 0
+		{1, 12, 0, 1, 0x7f},                                        // rank past the hash
+		{1, 12, 0, 2, 0x41, 0x01},                                  // an index twice
+		{1, 4, 1, 62, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, // register past the hash
+	} {
+		if err := c.UnmarshalBinary(bad); err != set.ErrMalformed {
+			t.Errorf("% x: want %v, got %v", bad, set.ErrMalformed, err)
+		}
+	}
+}
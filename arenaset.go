@@ -0,0 +1,153 @@
+package set
+
+import (
+	"github.com/dgryski/go-farm"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	arenaSetIsMutable MutableSet = NewArenaSet(0)
+	arenaSetIsList    ListSet    = NewArenaSet(0)
+)
+
+// ArenaSet is a set of string holding its keys in a chunked arena, indexed
+// by a linear probing table whose slots hold no pointers: where the key is
+// and 32 bits of its hash. A GoMap costs the garbage collector a pointer
+// per key, an ArenaSet a pointer per megabyte of keys.
+type ArenaSet struct {
+	arena chunkArena
+	slots []arenaSlot
+	mask  uint64 // slots - 1
+	n     int
+
+	garbage int // bytes of the arena held by deleted keys
+}
+
+// arenaSlot is where a key is in the arena. chunk is off by one, so the
+// zero slot is empty.
+type arenaSlot struct {
+	hash            uint32
+	chunk, pos, len uint32
+}
+
+// NewArenaSet creates an ArenaSet of capacity n.
+func NewArenaSet(n int) *ArenaSet {
+	slots := uint64(8)
+	for slots*7/8 < uint64(n) {
+		slots <<= 1
+	}
+	return &ArenaSet{slots: make([]arenaSlot, slots), mask: slots - 1}
+}
+
+func arenaHash(s string) uint32 { return farm.Hash32([]byte(s)) }
+
+func (as *ArenaSet) key(sl arenaSlot) []byte {
+	return as.arena.bytes(sl.chunk-1, sl.pos, sl.len)
+}
+
+// find gives the slot of the key, or of the empty slot where it would go,
+// and whether it was found.
+func (as *ArenaSet) find(s string, h uint32) (uint64, bool) {
+	i := uint64(h) & as.mask
+	for {
+		sl := as.slots[i]
+		if sl.chunk == 0 {
+			return i, false
+		}
+		if sl.hash == h && int(sl.len) == len(s) && string(as.key(sl)) == s {
+			return i, true
+		}
+		i = (i + 1) & as.mask
+	}
+}
+
+// place puts a slot in the first empty slot from its home.
+func (as *ArenaSet) place(sl arenaSlot) {
+	i := uint64(sl.hash) & as.mask
+	for as.slots[i].chunk != 0 {
+		i = (i + 1) & as.mask
+	}
+	as.slots[i] = sl
+}
+
+// rehash moves the keys to a table of the given size. Once deleted keys
+// hold more of the arena than live ones, it is copied to a new one.
+func (as *ArenaSet) rehash(size uint64) {
+	old, oldArena := as.slots, as.arena
+	compact := as.garbage > oldArena.size()/2
+	as.slots = make([]arenaSlot, size)
+	as.mask = size - 1
+	if compact {
+		as.arena = chunkArena{}
+		as.garbage = 0
+	}
+	for _, sl := range old {
+		if sl.chunk == 0 {
+			continue
+		}
+		if compact {
+			chunk, pos := as.arena.move(&oldArena, sl.chunk-1, sl.pos, sl.len)
+			sl.chunk, sl.pos = chunk+1, pos
+		}
+		as.place(sl)
+	}
+}
+
+// Add the key to the set.
+func (as *ArenaSet) Add(s string) {
+	h := arenaHash(s)
+	if _, ok := as.find(s, h); ok {
+		return
+	}
+	if uint64(as.n+1) > uint64(len(as.slots))*7/8 {
+		as.rehash(2 * uint64(len(as.slots)))
+	}
+	chunk, pos := as.arena.add(s)
+	as.place(arenaSlot{hash: h, chunk: chunk + 1, pos: pos, len: uint32(len(s))})
+	as.n++
+}
+
+// Contains tells if this key is in the set.
+func (as *ArenaSet) Contains(s string) bool {
+	_, ok := as.find(s, arenaHash(s))
+	return ok
+}
+
+// Delete the element form this set. The slots that follow it are moved
+// back into the hole when it is on their way, so no tombstone is left.
+func (as *ArenaSet) Delete(s string) {
+	hole, ok := as.find(s, arenaHash(s))
+	if !ok {
+		return
+	}
+	as.garbage += int(as.slots[hole].len)
+	for i := (hole + 1) & as.mask; as.slots[i].chunk != 0; i = (i + 1) & as.mask {
+		// a slot moves back if its home is not between the hole and it
+		if (i-uint64(as.slots[i].hash))&as.mask >= (i-hole)&as.mask {
+			as.slots[hole] = as.slots[i]
+			hole = i
+		}
+	}
+	as.slots[hole] = arenaSlot{}
+	as.n--
+	if as.garbage > arenaChunkSize && as.garbage > as.arena.size()/2 {
+		as.rehash(uint64(len(as.slots)))
+	}
+}
+
+// IsEmpty tells if this set is empty.
+func (as *ArenaSet) IsEmpty() bool { return as.n == 0 }
+
+// Len is the length of this set.
+func (as *ArenaSet) Len() int { return as.n }
+
+// Keys gives all the keys in this ArenaSet, in no particular order.
+func (as *ArenaSet) Keys() []string {
+	keys := make([]string, 0, as.n)
+	for _, sl := range as.slots {
+		if sl.chunk != 0 {
+			keys = append(keys, string(as.key(sl)))
+		}
+	}
+	return keys
+}
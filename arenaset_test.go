@@ -0,0 +1,57 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestArenaSet_Empty(t *testing.T) { setTest(t, set.NewArenaSet(0), []string{}) }
+func TestArenaSet_One(t *testing.T)   { setTest(t, set.NewArenaSet(0), []string{"A"}) }
+func TestArenaSet_Many(t *testing.T)  { setTest(t, set.NewArenaSet(0), []string{"A", "B", "C"}) }
+func TestArenaSet_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return set.NewArenaSet(0) })
+}
+
+func TestArenaSet_100Many(t *testing.T) {
+	setTest(t, set.NewArenaSet(100), []string{"A", "B", "C"})
+}
+
+func TestArenaSet_Web2(t *testing.T) { setTest(t, set.NewArenaSet(0), web2) }
+
+func TestArenaSet_LongKeys(t *testing.T) {
+	// keys longer than a chunk get their own
+	long := strings.Repeat("x", 3<<20)
+	setTest(t, set.NewArenaSet(0), []string{"A", long, long + "y", "B"})
+}
+
+func TestArenaSet_Churn(t *testing.T) {
+	// deleting keys of a few megabytes fills the arena with garbage,
+	// which must be reclaimed without losing the live keys
+	as := set.NewArenaSet(0)
+	pad := strings.Repeat("-", 1000)
+	for round := 0; round < 20; round++ {
+		for i := 0; i < 1000; i++ {
+			as.Add(pad + strconv.Itoa(round*1000+i))
+		}
+		for i := 0; i < 1000; i++ {
+			if i%10 != 0 {
+				as.Delete(pad + strconv.Itoa(round*1000+i))
+			}
+		}
+	}
+	var want []string
+	for i := 0; i < 20000; i += 10 {
+		want = append(want, pad+strconv.Itoa(i))
+	}
+	if as.Len() != len(want) {
+		t.Fatalf("want %d keys, got %d", len(want), as.Len())
+	}
+	for _, k := range want {
+		if !as.Contains(k) {
+			t.Fatalf("should contain %q", k)
+		}
+	}
+	listableTest(t, as, want)
+}
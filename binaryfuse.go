@@ -0,0 +1,328 @@
+package set
+
+import (
+	"github.com/dgryski/go-farm"
+	"math"
+	"math/bits"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	binaryFuseIsChecked       CheckedSet       = NewBinaryFuse(NewGoMap(0), 8)
+	binaryFuseIsProbabilistic ProbabilisticSet = NewBinaryFuse(NewGoMap(0), 8)
+)
+
+// parallelBuildThreshold is the number of keys from which hashing and
+// sorting them is spread over goroutines.
+const parallelBuildThreshold = 1 << 16
+
+// BinaryFuse is a static set of string implemented using a binary fuse
+// filter. Each key maps to three slots in consecutive segments of an
+// array of 8 or 16 bits fingerprints, the XOR of which is the key's own
+// fingerprint. It costs about 9 bits per key with 8 bits fingerprints,
+// with a false positive rate of 1/256.
+type BinaryFuse struct {
+	seed uint64
+	n    int
+
+	segmentLength      uint32
+	segmentCount       uint32
+	segmentCountLength uint32
+
+	fp8  []uint8
+	fp16 []uint16
+}
+
+// NewBinaryFuse builds a BinaryFuse holding the keys of s, with
+// fingerprints of fingerprintBits bits, either 8 or 16. For many keys, the
+// keys are hashed and their hashes sorted over many goroutines. Peeling
+// the keys off the array and assigning their fingerprints run on a single
+// goroutine, as each step depends on the ones before it.
+func NewBinaryFuse(s ListSet, fingerprintBits uint) *BinaryFuse {
+	if fingerprintBits != 8 && fingerprintBits != 16 {
+		panic("set: binary fuse fingerprints must have 8 or 16 bits")
+	}
+	keys := s.Keys()
+	hashes := hashKeys(keys)
+
+	f := &BinaryFuse{n: len(keys)}
+	rng := uint64(len(hashes))
+	for attempt := 0; ; attempt++ {
+		// a few unlucky seeds in a row mean the array is too tight
+		f.size(len(hashes), uint32(attempt/10))
+		f.seed = splitmix64(&rng)
+		if order, slots, ok := f.peel(hashes); ok {
+			f.assign(order, slots, fingerprintBits)
+			return f
+		}
+	}
+}
+
+// hashKeys hashes the keys and sorts their hashes, over many goroutines
+// if there are many keys, and removes the duplicates.
+func hashKeys(keys []string) []uint64 {
+	hashes := make([]uint64, len(keys))
+	runs := parallelChunks(len(keys), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			hashes[i] = farm.Hash64([]byte(keys[i]))
+		}
+		sort.Sort(uint64Slice(hashes[lo:hi]))
+	})
+	hashes = mergeRuns(hashes, runs)
+
+	// distinct keys with the same hash are the same to the filter
+	uniq := hashes[:0]
+	for i, h := range hashes {
+		if i == 0 || h != hashes[i-1] {
+			uniq = append(uniq, h)
+		}
+	}
+	return uniq
+}
+
+// parallelChunks calls f on chunks of [0, n), one per goroutine if n is
+// large enough, and gives the bounds of the chunks.
+func parallelChunks(n int, f func(lo, hi int)) []int {
+	workers := runtime.GOMAXPROCS(0)
+	if n < parallelBuildThreshold {
+		workers = 1
+	}
+	chunk := (n + workers - 1) / workers
+	bounds := []int{0}
+	var wg sync.WaitGroup
+	for lo := 0; lo < n; lo += chunk {
+		hi := lo + chunk
+		if hi > n {
+			hi = n
+		}
+		bounds = append(bounds, hi)
+		if workers == 1 {
+			f(lo, hi)
+			continue
+		}
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			f(lo, hi)
+		}(lo, hi)
+	}
+	wg.Wait()
+	return bounds
+}
+
+// mergeRuns merges the sorted runs of a between bounds, pairs of runs at
+// once in goroutines, until a single run is left.
+func mergeRuns(a []uint64, bounds []int) []uint64 {
+	if len(bounds) <= 2 {
+		return a
+	}
+	buf := make([]uint64, len(a))
+	for len(bounds) > 2 {
+		next := []int{0}
+		var wg sync.WaitGroup
+		for i := 0; i+1 < len(bounds); i += 2 {
+			lo, mid, hi := bounds[i], bounds[i+1], bounds[i+1]
+			if i+2 < len(bounds) {
+				hi = bounds[i+2]
+			}
+			next = append(next, hi)
+			wg.Add(1)
+			go func(lo, mid, hi int) {
+				defer wg.Done()
+				mergeUint64(buf[lo:hi], a[lo:mid], a[mid:hi])
+			}(lo, mid, hi)
+		}
+		wg.Wait()
+		a, buf, bounds = buf, a, next
+	}
+	return a
+}
+
+// mergeUint64 merges the sorted a and b into dst.
+func mergeUint64(dst, a, b []uint64) {
+	for len(a) != 0 && len(b) != 0 {
+		if a[0] <= b[0] {
+			dst[0], a = a[0], a[1:]
+		} else {
+			dst[0], b = b[0], b[1:]
+		}
+		dst = dst[1:]
+	}
+	copy(dst[copy(dst, a):], b)
+}
+
+type uint64Slice []uint64
+
+func (p uint64Slice) Len() int           { return len(p) }
+func (p uint64Slice) Less(i, j int) bool { return p[i] < p[j] }
+func (p uint64Slice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// size picks the segments for n keys, as tuned by Graf and Lemire for
+// filters of arity 3, plus extra segments.
+func (f *BinaryFuse) size(n int, extra uint32) {
+	f.segmentLength = 4
+	if n > 0 {
+		f.segmentLength = 1 << uint(math.Floor(math.Log(float64(n))/math.Log(3.33)+2.25))
+	}
+	if f.segmentLength > 1<<18 {
+		f.segmentLength = 1 << 18
+	}
+	capacity := 0.0
+	if n > 1 {
+		sizeFactor := math.Max(1.125, 0.875+0.25*math.Log(1e6)/math.Log(float64(n)))
+		capacity = math.Round(float64(n) * sizeFactor)
+	}
+	segments := int(math.Ceil(capacity/float64(f.segmentLength))) - 2
+	if segments < 1 {
+		segments = 1
+	}
+	f.segmentCount = uint32(segments) + extra
+	f.segmentCountLength = f.segmentCount * f.segmentLength
+}
+
+func (f *BinaryFuse) arrayLength() uint32 { return (f.segmentCount + 2) * f.segmentLength }
+
+// slots gives the three slots of a hash, one in each of three
+// consecutive segments.
+func (f *BinaryFuse) slots(h uint64) (uint32, uint32, uint32) {
+	hi, _ := bits.Mul64(h, uint64(f.segmentCountLength))
+	h0 := uint32(hi)
+	h1 := h0 + f.segmentLength
+	h2 := h1 + f.segmentLength
+	mask := f.segmentLength - 1
+	h1 ^= uint32(h>>18) & mask
+	h2 ^= uint32(h) & mask
+	return h0, h1, h2
+}
+
+// peel repeatedly removes a key that is alone in one of its slots. It
+// succeeds if every key gets removed, giving the order in which they were
+// and the slot that each one owns.
+func (f *BinaryFuse) peel(hashes []uint64) ([]uint64, []uint32, bool) {
+	length := f.arrayLength()
+	count := make([]uint32, length)
+	xorHash := make([]uint64, length)
+	for _, k := range hashes {
+		h := mix64(k + f.seed)
+		h0, h1, h2 := f.slots(h)
+		count[h0]++
+		count[h1]++
+		count[h2]++
+		xorHash[h0] ^= h
+		xorHash[h1] ^= h
+		xorHash[h2] ^= h
+	}
+
+	var alone []uint32
+	for i, c := range count {
+		if c == 1 {
+			alone = append(alone, uint32(i))
+		}
+	}
+
+	order := make([]uint64, 0, len(hashes))
+	owned := make([]uint32, 0, len(hashes))
+	for len(alone) != 0 {
+		i := alone[len(alone)-1]
+		alone = alone[:len(alone)-1]
+		if count[i] != 1 {
+			continue
+		}
+		h := xorHash[i]
+		order = append(order, h)
+		owned = append(owned, i)
+
+		h0, h1, h2 := f.slots(h)
+		for _, j := range [3]uint32{h0, h1, h2} {
+			count[j]--
+			xorHash[j] ^= h
+			if count[j] == 1 {
+				alone = append(alone, j)
+			}
+		}
+	}
+	return order, owned, len(order) == len(hashes)
+}
+
+func fingerprint(h uint64) uint64 { return h ^ h>>32 }
+
+// assign sets the fingerprints in the reverse order of peeling, so the
+// slot owned by each key is the last of its three to be set.
+func (f *BinaryFuse) assign(order []uint64, owned []uint32, fingerprintBits uint) {
+	length := f.arrayLength()
+	if fingerprintBits == 8 {
+		f.fp8 = make([]uint8, length)
+	} else {
+		f.fp16 = make([]uint16, length)
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		h := order[i]
+		h0, h1, h2 := f.slots(h)
+		if f.fp8 != nil {
+			f.fp8[owned[i]] = uint8(fingerprint(h)) ^ f.fp8[h0] ^ f.fp8[h1] ^ f.fp8[h2]
+		} else {
+			f.fp16[owned[i]] = uint16(fingerprint(h)) ^ f.fp16[h0] ^ f.fp16[h1] ^ f.fp16[h2]
+		}
+	}
+}
+
+// Add is not supported, a BinaryFuse is immutable.
+func (f *BinaryFuse) Add(s string) { panic(ErrImmutable) }
+
+// TryAdd always returns ErrImmutable.
+func (f *BinaryFuse) TryAdd(s string) error { return ErrImmutable }
+
+// Contains tells if this key was probably in the set.
+func (f *BinaryFuse) Contains(s string) bool {
+	h := mix64(farm.Hash64([]byte(s)) + f.seed)
+	h0, h1, h2 := f.slots(h)
+	if f.fp8 != nil {
+		return uint8(fingerprint(h))^f.fp8[h0]^f.fp8[h1]^f.fp8[h2] == 0
+	}
+	return uint16(fingerprint(h))^f.fp16[h0]^f.fp16[h1]^f.fp16[h2] == 0
+}
+
+// IsEmpty tells if this set is empty.
+func (f *BinaryFuse) IsEmpty() bool { return f.n == 0 }
+
+// Len is the length of this set.
+func (f *BinaryFuse) Len() int { return f.n }
+
+// EstimatedFalsePositiveRate is the probability that Contains is true
+// for a key that was never added.
+func (f *BinaryFuse) EstimatedFalsePositiveRate() float64 {
+	if f.fp8 != nil {
+		return 1.0 / (1 << 8)
+	}
+	return 1.0 / (1 << 16)
+}
+
+// Capacity is the length of this set, it was built with all its keys.
+func (f *BinaryFuse) Capacity() int { return f.n }
+
+// FalsePositiveRate is the probability that Contains is true for a key
+// that was never added.
+func (f *BinaryFuse) FalsePositiveRate() float64 { return f.EstimatedFalsePositiveRate() }
+
+// IsExact is false, a BinaryFuse may claim to contain keys it never saw.
+func (f *BinaryFuse) IsExact() bool { return false }
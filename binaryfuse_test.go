@@ -0,0 +1,44 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"strconv"
+	"testing"
+)
+
+func buildBinaryFuse(bits uint) func(set.ListSet) set.Set {
+	return func(s set.ListSet) set.Set { return set.NewBinaryFuse(s, bits) }
+}
+
+func TestBinaryFuse_Empty(t *testing.T) { staticTest(t, buildBinaryFuse(8), []string{}) }
+func TestBinaryFuse_One(t *testing.T)   { staticTest(t, buildBinaryFuse(8), []string{"A"}) }
+func TestBinaryFuse_Many(t *testing.T)  { staticTest(t, buildBinaryFuse(8), []string{"A", "B", "C"}) }
+func TestBinaryFuse_Words(t *testing.T) {
+	staticTest(t, buildBinaryFuse(8), setA.Keys())
+	staticTest(t, buildBinaryFuse(16), setA.Keys())
+}
+
+func TestBinaryFuse_FalsePositiveRate(t *testing.T) {
+	for _, bits := range []uint{8, 16} {
+		f := set.NewBinaryFuse(setFromList(web2[:len(web2)/2]), bits)
+		fpTest(t, f, web2[len(web2)/2:], f.EstimatedFalsePositiveRate())
+	}
+}
+
+func TestBinaryFuse_Parallel(t *testing.T) {
+	// enough keys to hash and sort them over goroutines
+	keys := set.NewGoMap(0)
+	for i := 0; i < 200000; i++ {
+		keys.Add(strconv.Itoa(i % 150000))
+		keys.Add("k" + strconv.Itoa(i))
+	}
+	f := set.NewBinaryFuse(keys, 8)
+	if f.Len() != keys.Len() {
+		t.Fatalf("want %d keys, got %d", keys.Len(), f.Len())
+	}
+	for _, k := range keys.Keys() {
+		if !f.Contains(k) {
+			t.Fatalf("should contain %q", k)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package set
+
+import (
+	"github.com/dgryski/go-farm"
+	"math"
+	"math/bits"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	bloomIsSet           Set              = NewBloom(0, 0.01)
+	bloomIsProbabilistic ProbabilisticSet = NewBloom(0, 0.01)
+)
+
+// Bloom is a set of string implemented using a Bloom filter. It may claim
+// to contain keys that were never added, but never forgets one that was.
+type Bloom struct {
+	bits     []uint64
+	m        uint64 // number of bits
+	k        uint64 // number of hash functions
+	n        int
+	capacity int
+	fpRate   float64
+}
+
+// NewBloom creates a Bloom sized to hold expectedN keys with a false
+// positive rate of fpRate, which must be between 0 and 1.
+func NewBloom(expectedN int, fpRate float64) *Bloom {
+	m, k := bloomSize(expectedN, fpRate)
+	return &Bloom{
+		bits:     make([]uint64, m/64),
+		m:        m,
+		k:        k,
+		capacity: expectedN,
+		fpRate:   fpRate,
+	}
+}
+
+// bloomSize gives the number of bits, a multiple of 64, and the number
+// of hash functions that hold n keys at a false positive rate of p.
+func bloomSize(n int, p float64) (m, k uint64) {
+	if !(p > 0 && p < 1) {
+		panic("set: bloom filter false positive rate must be between 0 and 1")
+	}
+	if n < 1 {
+		n = 1
+	}
+	bitsPerKey := -math.Log(p) / (math.Ln2 * math.Ln2)
+	m = uint64(math.Ceil(float64(n)*bitsPerKey/64)) * 64
+	if m == 0 {
+		m = 64
+	}
+	k = uint64(math.Max(1, math.Round(bitsPerKey*math.Ln2)))
+	return m, k
+}
+
+// bloomHash gives the two hashes from which the k bit positions of a
+// key are derived: the i-th position is h1 + i*h2.
+func bloomHash(s string) (h1, h2 uint64) {
+	return farm.Hash128([]byte(s))
+}
+
+// Add the key to the set.
+func (b *Bloom) Add(s string) {
+	h1, h2 := bloomHash(s)
+	added := false
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		word, mask := pos/64, uint64(1)<<(pos%64)
+		if b.bits[word]&mask == 0 {
+			b.bits[word] |= mask
+			added = true
+		}
+	}
+	if added {
+		b.n++
+	}
+}
+
+// Contains tells if this key was probably in the set at least once.
+func (b *Bloom) Contains(s string) bool {
+	h1, h2 := bloomHash(s)
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		if b.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEmpty tells if this set is empty.
+func (b *Bloom) IsEmpty() bool { return b.n == 0 }
+
+// Len is the length of this set. It misses the keys that were false
+// positives when added.
+func (b *Bloom) Len() int { return b.n }
+
+// Capacity is the number of keys this set was sized for. Past it, the
+// false positive rate grows beyond the one it was created with.
+func (b *Bloom) Capacity() int { return b.capacity }
+
+// FalsePositiveRate is the false positive rate this set was created with.
+func (b *Bloom) FalsePositiveRate() float64 { return b.fpRate }
+
+// IsExact is false, a Bloom may claim to contain keys it never saw.
+func (b *Bloom) IsExact() bool { return false }
+
+func (b *Bloom) ones() uint64 {
+	var ones int
+	for _, w := range b.bits {
+		ones += bits.OnesCount64(w)
+	}
+	return uint64(ones)
+}
+
+// EstimatedFalsePositiveRate is the probability that Contains is true
+// for a key that was never added, given the bits set so far.
+func (b *Bloom) EstimatedFalsePositiveRate() float64 {
+	return math.Pow(float64(b.ones())/float64(b.m), float64(b.k))
+}
+
+// estimateLen gives the number of keys that likely set the bits of
+// this filter.
+func (b *Bloom) estimateLen() int {
+	ones := b.ones()
+	if ones == b.m {
+		return b.n
+	}
+	m, k := float64(b.m), float64(b.k)
+	return int(math.Round(-m / k * math.Log(1-float64(ones)/m)))
+}
+
+func (b *Bloom) combine(other *Bloom, op func(x, y uint64) uint64) (*Bloom, error) {
+	if b.m != other.m || b.k != other.k {
+		return nil, ErrIncompatible
+	}
+	out := &Bloom{
+		bits:     make([]uint64, len(b.bits)),
+		m:        b.m,
+		k:        b.k,
+		capacity: b.capacity,
+		fpRate:   b.fpRate,
+	}
+	for i := range out.bits {
+		out.bits[i] = op(b.bits[i], other.bits[i])
+	}
+	out.n = out.estimateLen()
+	return out, nil
+}
+
+// Union of this filter and other, which must have been created with the
+// same parameters. The result is exactly the filter that would hold the
+// keys of both.
+func (b *Bloom) Union(other *Bloom) (*Bloom, error) {
+	return b.combine(other, func(x, y uint64) uint64 { return x | y })
+}
+
+// Intersect this filter with other, which must have been created with
+// the same parameters. The result may have a higher false positive rate
+// than the filter that would hold only the keys in both.
+func (b *Bloom) Intersect(other *Bloom) (*Bloom, error) {
+	return b.combine(other, func(x, y uint64) uint64 { return x & y })
+}
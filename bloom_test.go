@@ -0,0 +1,99 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"math"
+	"testing"
+)
+
+func TestBloom_Empty(t *testing.T) { setTest(t, set.NewBloom(100, 0.001), []string{}) }
+func TestBloom_One(t *testing.T)   { setTest(t, set.NewBloom(100, 0.001), []string{"A"}) }
+func TestBloom_Many(t *testing.T)  { setTest(t, set.NewBloom(100, 0.001), []string{"A", "B", "C"}) }
+func TestBloom_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return set.NewBloom(100, 0.001) })
+}
+
+func TestBloom_FalsePositiveRate(t *testing.T) {
+	for _, fpRate := range []float64{0.1, 0.01} {
+		b := set.NewBloom(len(web2)/2, fpRate)
+		for _, k := range web2[:len(web2)/2] {
+			b.Add(k)
+		}
+		fpTest(t, b, web2[len(web2)/2:], fpRate)
+
+		if got := b.EstimatedFalsePositiveRate(); got > 2*fpRate {
+			t.Errorf("estimated false positive rate %g, want about %g", got, fpRate)
+		}
+	}
+}
+
+func TestBloom_Union(t *testing.T) {
+	a, b := set.NewBloom(len(web2), 0.01), set.NewBloom(len(web2), 0.01)
+	for i, k := range web2 {
+		if i%2 == 0 {
+			a.Add(k)
+		} else {
+			b.Add(k)
+		}
+	}
+
+	u, err := a.Union(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range web2 {
+		if !u.Contains(k) {
+			t.Fatalf("union should contain %q", k)
+		}
+	}
+
+	if _, err := a.Union(set.NewBloom(len(web2), 0.1)); err != set.ErrIncompatible {
+		t.Errorf("want %v, got %v", set.ErrIncompatible, err)
+	}
+}
+
+func TestBloom_Intersect(t *testing.T) {
+	a, b := set.NewBloom(len(web2), 0.01), set.NewBloom(len(web2), 0.01)
+	half := len(web2) / 2
+	for _, k := range web2[:half+half/2] {
+		a.Add(k)
+	}
+	for _, k := range web2[half/2:] {
+		b.Add(k)
+	}
+
+	inter, err := a.Intersect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range web2[half/2 : half+half/2] {
+		if !inter.Contains(k) {
+			t.Fatalf("intersection should contain %q", k)
+		}
+	}
+
+	if _, err := a.Intersect(set.NewBloom(2*len(web2), 0.01)); err != set.ErrIncompatible {
+		t.Errorf("want %v, got %v", set.ErrIncompatible, err)
+	}
+}
+
+func TestBloom_BadRate(t *testing.T) {
+	constructors := map[string]func(rate float64){
+		"Bloom":         func(rate float64) { set.NewBloom(100, rate) },
+		"CountingBloom": func(rate float64) { set.NewCountingBloom(100, rate) },
+		"ScalableBloom": func(rate float64) { set.NewScalableBloom(rate) },
+		"CountMin":      func(rate float64) { set.NewCountMin(rate, 0.01) },
+	}
+	for name, build := range constructors {
+		for _, rate := range []float64{0, -0.1, 1, math.NaN()} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s: should panic with a rate of %v", name, rate)
+					}
+				}()
+				build(rate)
+			}()
+		}
+	}
+}
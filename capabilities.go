@@ -0,0 +1,66 @@
+package set
+
+import (
+	"strings"
+)
+
+// Capability is a set of traits of a Set, beyond its own methods.
+type Capability uint
+
+const (
+	// Listable sets are ListSet.
+	Listable Capability = 1 << iota
+	// Mutable sets are MutableSet.
+	Mutable
+	// Ordered sets are OrderedSet.
+	Ordered
+	// Prefix sets are PrefixSet.
+	Prefix
+	// Probabilistic sets are ProbabilisticSet that are not exact.
+	Probabilistic
+	// Checked sets are CheckedSet.
+	Checked
+)
+
+var capabilityNames = []string{"listable", "mutable", "ordered", "prefix", "probabilistic", "checked"}
+
+// Capabilities tells the traits of s.
+func Capabilities(s Set) Capability {
+	var c Capability
+	if _, ok := s.(ListSet); ok {
+		c |= Listable
+	}
+	if _, ok := s.(MutableSet); ok {
+		c |= Mutable
+	}
+	if _, ok := s.(OrderedSet); ok {
+		c |= Ordered
+	}
+	if _, ok := s.(PrefixSet); ok {
+		c |= Prefix
+	}
+	if p, ok := s.(ProbabilisticSet); ok && !p.IsExact() {
+		c |= Probabilistic
+	}
+	if _, ok := s.(CheckedSet); ok {
+		c |= Checked
+	}
+	return c
+}
+
+// Has tells if c has all the traits of other.
+func (c Capability) Has(other Capability) bool { return c&other == other }
+
+// String lists the traits of c, separated by "|".
+func (c Capability) String() string {
+	var names []string
+	for i, name := range capabilityNames {
+		if c&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
@@ -0,0 +1,55 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	words := setFromList(web2)
+	tests := []struct {
+		s    set.Set
+		want set.Capability
+	}{
+		{set.NewGoMap(0), set.Listable | set.Mutable},
+		{set.NewSortedSlice(0), set.Listable | set.Mutable | set.Ordered},
+		{set.NewSortedSlice(0).Freeze(), set.Listable | set.Ordered | set.Checked},
+		{set.NewLOUDS(words), set.Listable | set.Prefix | set.Checked},
+		{set.NewBloom(100, 0.01), set.Probabilistic},
+		{set.NewCuckoo(100, 8), set.Mutable | set.Probabilistic | set.Checked},
+		{set.NewGolombSet(words, 0.01), set.Probabilistic | set.Checked},
+	}
+	for _, tt := range tests {
+		if got := set.Capabilities(tt.s); got != tt.want {
+			t.Errorf("%T: want %v, got %v", tt.s, tt.want, got)
+		}
+	}
+
+	if got := (set.Listable | set.Prefix).String(); got != "listable|prefix" {
+		t.Errorf("want %q, got %q", "listable|prefix", got)
+	}
+	if !(set.Listable | set.Mutable).Has(set.Mutable) {
+		t.Error("should have the mutable trait")
+	}
+}
+
+func TestProbabilisticSet(t *testing.T) {
+	sets := []set.ProbabilisticSet{
+		set.NewBloom(100, 0.01),
+		set.NewCountingBloom(100, 0.01),
+		set.NewScalableBloom(0.01),
+		set.NewCuckoo(100, 12),
+		set.NewQuotient(100, 8),
+	}
+	for _, s := range sets {
+		if s.IsExact() {
+			t.Errorf("%T: should not be exact", s)
+		}
+		if s.Capacity() < 100 {
+			t.Errorf("%T: capacity %d, want at least 100", s, s.Capacity())
+		}
+		if r := s.FalsePositiveRate(); r <= 0 || r > 0.01 {
+			t.Errorf("%T: false positive rate %g, want at most 0.01", s, r)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package set
+
+import (
+	"math"
+)
+
+const (
+	// arenaChunkSize is the size the chunks of an arena grow to. A longer
+	// key gets a chunk of its own.
+	arenaChunkSize = 1 << 20
+	arenaMinChunk  = 4 << 10
+)
+
+// chunkArena keeps strings back to back in chunks of bytes that are never
+// moved once allocated, so the garbage collector sees a pointer per chunk
+// rather than per string. It is where the sets of this package keep their
+// keys: a string is known by its chunk, its position and its length, all
+// uint32, so an arena can outgrow 4GB. Dropping deleted strings is done by
+// moving the live ones to a new arena.
+type chunkArena struct {
+	chunks [][]byte
+}
+
+// add copies s in the arena, telling where it went.
+func (a *chunkArena) add(s string) (chunk, pos uint32) {
+	chunk, pos = a.reserve(len(s))
+	copy(a.bytes(chunk, pos, uint32(len(s))), s)
+	return chunk, pos
+}
+
+// reserve makes room for n bytes at the end of the last chunk, starting a
+// new one if they don't fit. It panics with ErrOverflow if n or the number
+// of chunks don't fit in an uint32, less one for the sets which keep a
+// chunk off by one.
+func (a *chunkArena) reserve(n int) (chunk, pos uint32) {
+	if uint64(n) > math.MaxUint32 {
+		panic(ErrOverflow)
+	}
+	last := len(a.chunks) - 1
+	if last < 0 || cap(a.chunks[last])-len(a.chunks[last]) < n {
+		if uint64(len(a.chunks)) >= math.MaxUint32-1 {
+			panic(ErrOverflow)
+		}
+		size := arenaMinChunk
+		if last >= 0 {
+			size = 2 * cap(a.chunks[last])
+		}
+		if size > arenaChunkSize {
+			size = arenaChunkSize
+		}
+		if size < n {
+			size = n
+		}
+		a.chunks = append(a.chunks, make([]byte, 0, size))
+		last++
+	}
+	pos = uint32(len(a.chunks[last]))
+	a.chunks[last] = a.chunks[last][:int(pos)+n]
+	return uint32(last), pos
+}
+
+// bytes gives the n bytes of the string at pos in chunk, without copying.
+func (a *chunkArena) bytes(chunk, pos, n uint32) []byte {
+	return a.chunks[chunk][pos : pos+n]
+}
+
+// move copies the n bytes at pos in chunk of from, telling where they
+// went.
+func (a *chunkArena) move(from *chunkArena, chunk, pos, n uint32) (uint32, uint32) {
+	to, at := a.reserve(int(n))
+	copy(a.bytes(to, at, n), from.bytes(chunk, pos, n))
+	return to, at
+}
+
+func (a *chunkArena) size() int {
+	size := 0
+	for _, c := range a.chunks {
+		size += len(c)
+	}
+	return size
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"flag"
+	"github.com/aybabtme/set"
 	"github.com/aybabtme/uniplot/histogram"
 	"github.com/dustin/go-humanize"
 	"io"
@@ -16,6 +17,8 @@ func main() {
 	nP := flag.Int("n", 1, "length of words to count")
 	topP := flag.Int("top", 50, "top words occuring most frequently")
 	ignoreP := flag.String("ignore", "", "ignore a word while counting")
+	approxP := flag.Bool("approx", false, "count in bounded memory, with error bounds")
+	epsilonP := flag.Float64("epsilon", 1e-5, "relative error of approximate counts")
 	flag.Parse()
 	// N = NP lol
 	n := *nP
@@ -25,6 +28,10 @@ func main() {
 
 	rd := bufio.NewReader(os.Stdin)
 	count := make(map[string]int64)
+	var approx *approxCount
+	if *approxP {
+		approx = newApproxCount(top, *epsilonP)
+	}
 	word := make([]byte, n)
 	var lineLengths []float64
 reading:
@@ -42,13 +49,24 @@ reading:
 
 		for i := 0; i < len(line)-n; i++ {
 			word = line[i : i+n]
-			if !shouldIgnore {
-				count[string(word)]++
-			} else if !bytes.Contains(word, ignore) {
+			if shouldIgnore && bytes.Contains(word, ignore) {
+				continue
+			}
+			if approx != nil {
+				approx.add(string(word))
+			} else {
 				count[string(word)]++
 			}
+		}
+	}
 
+	if approx != nil {
+		approx.print(top, n)
+		if shouldIgnore {
+			log.Printf("ignoring %q", string(ignore))
 		}
+		printHistogram(top, lineLengths)
+		return
 	}
 
 	var totalWord int64
@@ -80,12 +98,68 @@ reading:
 		100*float64(topkWord)/float64(totalWord),
 	)
 
+	printHistogram(top, lineLengths)
+}
+
+func printHistogram(top int, lineLengths []float64) {
 	h := histogram.Hist(top, lineLengths)
 	histogram.Fprintf(os.Stdout, h, histogram.Linear(70), func(v float64) string {
 		return humanize.Comma(int64(v))
 	})
 }
 
+// approxCount counts words in bounded memory: a count-min sketch bounds
+// the count of any word, a top-k tracker finds the most frequent ones and
+// a cardinality sketch estimates how many are unique.
+type approxCount struct {
+	cm     *set.CountMin
+	topk   *set.TopK
+	unique *set.ApproxCounter
+}
+
+func newApproxCount(top int, epsilon float64) *approxCount {
+	return &approxCount{
+		cm: set.NewCountMin(epsilon, 0.001),
+		// extra counters keep the error of the top entries low
+		topk:   set.NewTopK(10 * top),
+		unique: set.NewFarmApproxCounter(14),
+	}
+}
+
+func (a *approxCount) add(word string) {
+	a.cm.Add(word)
+	a.topk.Add(word)
+	a.unique.Add(word)
+}
+
+func (a *approxCount) print(top, n int) {
+	entries := a.topk.Top()
+	if len(entries) > top {
+		entries = entries[:top]
+	}
+	var topkWord uint64
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		// both counts are upper bounds, each with its own lower bound
+		count, low := e.Count, e.Count-e.Error
+		if c := a.cm.Count(e.Key); c < count {
+			count = c
+		}
+		if bound := a.cm.ErrorBound(); count > bound && count-bound > low {
+			low = count - bound
+		}
+		log.Printf("\t%q\t%s\t(-%s)", e.Key, humanize.Comma(int64(count)), humanize.Comma(int64(count-low)))
+		topkWord += count
+	}
+
+	log.Printf("counts: ~%d unique strings of length %d, top %d are ~%.1f%% of that.",
+		a.unique.Estimate(),
+		n,
+		top,
+		100*float64(topkWord)/float64(a.cm.Total()),
+	)
+}
+
 func imax(a, b int) int {
 	if a > b {
 		return a
@@ -69,6 +69,7 @@ func memplotCommand() ([]cli.Flag, func(*cli.Context)) {
 		log.Printf("baseline-in-use=%s", humanize.Bytes(mem.HeapAlloc-mem.HeapReleased))
 
 		log.Printf("key-count=%d", len(keys))
+		logCollisionRisks(keys)
 
 		log.Printf("doing %d benchmarks", len(sets))
 		for i, s := range sets {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"crypto"
 	"fmt"
 	"github.com/aybabtme/set"
 	"github.com/codegangsta/cli"
@@ -68,15 +69,42 @@ type setimpl struct {
 }
 
 var impls = map[string]setimpl{
-	"gomap":       {name: "GoMap", s: func() set.Set { return set.NewGoMap(0) }},
-	"hashsha1":    {name: "HashSHA1", s: func() set.Set { return set.NewHashSHA1(0, true) }},
-	"spooky128":   {name: "Spooky128", s: func() set.Set { return set.NewSpooky128(0, true) }},
-	"farmhash128": {name: "Farmhash128", s: func() set.Set { return set.NewFarm128(0, true) }},
-	"spooky64":    {name: "Spooky64", s: func() set.Set { return set.NewSpooky64(0, true) }},
-	"farmhash64":  {name: "Farmhash64", s: func() set.Set { return set.NewFarm64(0, true) }},
-	"ternary":     {name: "TernarySet", s: func() set.Set { return set.NewTernarySet() }},
-	"tchappat":    {name: "TchapPatricia", s: func() set.Set { return set.NewTchapPatricia() }},
-	"quicktrie":   {name: "Quicktrie", s: func() set.Set { return set.NewQuicktrie() }},
+	"gomap":         {name: "GoMap", s: func() set.Set { return set.NewGoMap(0) }},
+	"arena":         {name: "ArenaSet", s: func() set.Set { return set.NewArenaSet(0) }},
+	"swiss":         {name: "SwissTable", s: func() set.Set { return set.NewSwissTable(0) }},
+	"hashsha1":      {name: "HashSHA1", s: func() set.Set { return set.NewHashSHA1(0, true) }},
+	"hashsha256":    {name: "HashSHA256_12", s: func() set.Set { return set.NewHashCrypto(0, crypto.SHA256, 12, true) }},
+	"spooky128":     {name: "Spooky128", s: func() set.Set { return set.NewSpooky128(0, true) }},
+	"farmhash128":   {name: "Farmhash128", s: func() set.Set { return set.NewFarm128(0, true) }},
+	"spooky64":      {name: "Spooky64", s: func() set.Set { return set.NewSpooky64(0, true) }},
+	"farmhash64":    {name: "Farmhash64", s: func() set.Set { return set.NewFarm64(0, true) }},
+	"farmhash64rh":  {name: "Farmhash64RobinHood", s: func() set.Set { return set.NewFarmRobinHood64(0, true) }},
+	"ternary":       {name: "TernarySet", s: func() set.Set { return set.NewTernarySet() }},
+	"tchappat":      {name: "TchapPatricia", s: func() set.Set { return set.NewTchapPatricia() }},
+	"quicktrie":     {name: "Quicktrie", s: func() set.Set { return set.NewQuicktrie() }},
+	"scalablebloom": {name: "ScalableBloom", s: func() set.Set { return set.NewScalableBloom(0.001) }},
+}
+
+// logCollisionRisks tells how likely the digests of keys collide in the
+// hash based sets, and how many keys each digest width can take.
+func logCollisionRisks(keys []string) {
+	const maxRisk = 1e-9
+	for _, width := range []struct {
+		name string
+		bits uint
+	}{
+		{"Hash64", 64},
+		{"HashSHA256_12", 96},
+		{"Hash128", 128},
+		{"HashSHA1", 160},
+	} {
+		log.Printf("collision-risk=%s	bits=%d	p=%.3g	max-keys-at-p=%g:%d",
+			width.name,
+			width.bits,
+			set.CollisionProbability(len(keys), width.bits),
+			maxRisk,
+			set.MaxKeysForRisk(maxRisk, width.bits))
+	}
 }
 
 func decodeKeys(r io.Reader) (out []string, err error) {
@@ -98,6 +126,14 @@ func setImpl(settype string) (out []setimpl, err error) {
 		return out, nil
 	}
 
+	if kind, hasher := splitHasherType(settype); hasher != "" {
+		impl, err := namedHashImpl(kind, hasher)
+		if err != nil {
+			return nil, err
+		}
+		return append(out, impl), nil
+	}
+
 	impl, ok := impls[settype]
 	if !ok {
 		var keys []string
@@ -105,7 +141,7 @@ func setImpl(settype string) (out []setimpl, err error) {
 			keys = append(keys, k)
 		}
 
-		return nil, fmt.Errorf("%q is not a valid set type, valids types are: %s",
+		return nil, fmt.Errorf("%q is not a valid set type, valids types are: %s, or hash64:<hasher> and hash128:<hasher>",
 			settype,
 			strings.Join(keys, ", "))
 	}
@@ -114,3 +150,47 @@ func setImpl(settype string) (out []setimpl, err error) {
 
 	return
 }
+
+// splitHasherType splits types like "hash64:siphash" in the kind of hash
+// set and the name of its hasher.
+func splitHasherType(settype string) (kind, hasher string) {
+	i := strings.Index(settype, ":")
+	if i < 0 {
+		return settype, ""
+	}
+	return settype[:i], settype[i+1:]
+}
+
+func namedHashImpl(kind, hasher string) (setimpl, error) {
+	var names []string
+	switch kind {
+	case "hash64":
+		if _, err := set.NewNamedHash64(0, hasher, true); err == nil {
+			return setimpl{
+				name: "Hash64_" + hasher,
+				s: func() set.Set {
+					h, _ := set.NewNamedHash64(0, hasher, true)
+					return h
+				},
+			}, nil
+		}
+		names = set.Hashers64()
+	case "hash128":
+		if _, err := set.NewNamedHash128(0, hasher, true); err == nil {
+			return setimpl{
+				name: "Hash128_" + hasher,
+				s: func() set.Set {
+					h, _ := set.NewNamedHash128(0, hasher, true)
+					return h
+				},
+			}, nil
+		}
+		names = set.Hashers128()
+	default:
+		return setimpl{}, fmt.Errorf("%q is not a kind of hash set, valid kinds are: hash64, hash128", kind)
+	}
+	return setimpl{}, fmt.Errorf("%q is not a registered hasher for %s, valid hashers are: %s",
+		hasher,
+		kind,
+		strings.Join(names, ", "))
+}
@@ -65,6 +65,7 @@ func timeplotCommand() ([]cli.Flag, func(*cli.Context)) {
 		}
 
 		log.Printf("key-count=%d", len(keys))
+		logCollisionRisks(keys)
 
 		log.Printf("doing %d benchmarks", len(sets))
 		for i, s := range sets {
@@ -0,0 +1,55 @@
+package set
+
+import (
+	"math"
+)
+
+// maxInt is the largest int.
+const maxInt = int(^uint(0) >> 1)
+
+// CollisionProbability is the probability that at least two of n keys
+// have the same digest of a width of bits, by the birthday bound. Hash
+// based sets cannot tell such keys apart.
+func CollisionProbability(n int, bits uint) float64 {
+	if n < 2 {
+		return 0
+	}
+	pairs := float64(n) * float64(n-1) / 2
+	return -math.Expm1(-pairs / math.Ldexp(1, int(bits)))
+}
+
+// MaxKeysForRisk is the largest number of keys whose digests of a width
+// of bits collide with a probability of at most p.
+func MaxKeysForRisk(p float64, bits uint) int {
+	if p <= 0 {
+		return 1
+	}
+	if p >= 1 {
+		return maxInt
+	}
+	// solve n(n-1)/2 = -ln(1-p) * 2^bits for n
+	pairs := -math.Log1p(-p) * math.Ldexp(1, int(bits))
+	n := math.Floor((1 + math.Sqrt(1+8*pairs)) / 2)
+	if n >= float64(maxInt) {
+		return maxInt
+	}
+	return int(n)
+}
+
+// CollisionRisk is the probability that two keys of this set have the
+// same digest, given its length.
+func (m *Hash64) CollisionRisk() float64 { return CollisionProbability(m.Len(), 64) }
+
+// CollisionRisk is the probability that two keys of this set have the
+// same digest, given its length.
+func (m *Hash128) CollisionRisk() float64 { return CollisionProbability(m.Len(), 128) }
+
+// CollisionRisk is the probability that two keys of this set have the
+// same digest, given its length.
+func (m *HashSHA1) CollisionRisk() float64 { return CollisionProbability(m.Len(), 160) }
+
+// CollisionRisk is the probability that two keys of this set have the
+// same truncated digest, given its length.
+func (m *HashCrypto) CollisionRisk() float64 {
+	return CollisionProbability(m.Len(), uint(8*m.width))
+}
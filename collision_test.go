@@ -0,0 +1,62 @@
+package set_test
+
+import (
+	"crypto"
+	"github.com/aybabtme/set"
+	"math"
+	"testing"
+)
+
+func TestCollisionProbability(t *testing.T) {
+	tests := []struct {
+		n    int
+		bits uint
+		want float64
+	}{
+		{0, 64, 0},
+		{1, 8, 0},
+		// the classic birthday problem, with 2^8 for 365 days
+		{23, 8, 0.6282},
+		{1 << 30, 60, 0.3935},
+		{1 << 20, 128, 1.6e-27},
+	}
+	for _, tt := range tests {
+		got := set.CollisionProbability(tt.n, tt.bits)
+		if math.Abs(got-tt.want) > tt.want*0.01 {
+			t.Errorf("n=%d bits=%d: want %g, got %g", tt.n, tt.bits, tt.want, got)
+		}
+	}
+}
+
+func TestMaxKeysForRisk(t *testing.T) {
+	for _, bits := range []uint{16, 32, 64, 96} {
+		for _, p := range []float64{1e-12, 1e-6, 0.01, 0.5} {
+			n := set.MaxKeysForRisk(p, bits)
+			if got := set.CollisionProbability(n, bits); got > p*1.0001 {
+				t.Errorf("bits=%d p=%g: %d keys collide with probability %g", bits, p, n, got)
+			}
+			// n is capped at the largest int, which 32-bit targets reach
+			if n+2 < n {
+				continue
+			}
+			if got := set.CollisionProbability(n+2, bits); got < p*0.9999 && n > 1 {
+				t.Errorf("bits=%d p=%g: %d keys is not the largest", bits, p, n)
+			}
+		}
+	}
+}
+
+func TestCollisionRisk(t *testing.T) {
+	h64 := set.NewFarm64(0, true)
+	h96 := set.NewHashCrypto(0, crypto.SHA256, 12, true)
+	for _, k := range web2 {
+		h64.Add(k)
+		h96.Add(k)
+	}
+	if want := set.CollisionProbability(len(web2), 64); h64.CollisionRisk() != want {
+		t.Errorf("Hash64: want %g, got %g", want, h64.CollisionRisk())
+	}
+	if h96.CollisionRisk() >= h64.CollisionRisk() {
+		t.Errorf("96 bits digests should be safer than 64 bits")
+	}
+}
@@ -0,0 +1,134 @@
+package set
+
+import (
+	"math"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	countingBloomIsMutable       MutableSet       = NewCountingBloom(0, 0.01)
+	countingBloomIsChecked       CheckedSet       = NewCountingBloom(0, 0.01)
+	countingBloomIsProbabilistic ProbabilisticSet = NewCountingBloom(0, 0.01)
+)
+
+const (
+	countBits     = 4
+	countsPerWord = 64 / countBits
+	countMax      = 1<<countBits - 1
+)
+
+// CountingBloom is a set of string implemented using a counting Bloom
+// filter: each bit of a Bloom filter becomes a 4 bits counter, so keys
+// can be deleted. Like a Bloom, it may claim to contain keys that were
+// never added.
+//
+// A counter that reaches 15 saturates and is never decremented again,
+// as its true count is lost. Keys are counted, not deduplicated: adding
+// a key twice takes deleting it twice.
+type CountingBloom struct {
+	counters []uint64
+	m        uint64 // number of counters
+	k        uint64 // number of hash functions
+	n        int
+	capacity int
+	fpRate   float64
+}
+
+// NewCountingBloom creates a CountingBloom sized to hold expectedN keys
+// with a false positive rate of fpRate, which must be between 0 and 1.
+func NewCountingBloom(expectedN int, fpRate float64) *CountingBloom {
+	m, k := bloomSize(expectedN, fpRate)
+	return &CountingBloom{
+		counters: make([]uint64, m/countsPerWord),
+		m:        m,
+		k:        k,
+		capacity: expectedN,
+		fpRate:   fpRate,
+	}
+}
+
+func (c *CountingBloom) count(pos uint64) uint64 {
+	return (c.counters[pos/countsPerWord] >> (pos % countsPerWord * countBits)) & countMax
+}
+
+func (c *CountingBloom) setCount(pos, count uint64) {
+	shift := pos % countsPerWord * countBits
+	word := &c.counters[pos/countsPerWord]
+	*word = *word&^(countMax<<shift) | count<<shift
+}
+
+// Add the key to the set, ignoring counter overflows.
+func (c *CountingBloom) Add(s string) { _ = c.TryAdd(s) }
+
+// TryAdd adds the key to the set. The key is added even if it returns
+// ErrOverflow, which tells that a counter saturated: deleting the keys
+// sharing this counter will not clear it.
+func (c *CountingBloom) TryAdd(s string) error {
+	h1, h2 := bloomHash(s)
+	var err error
+	for i := uint64(0); i < c.k; i++ {
+		pos := (h1 + i*h2) % c.m
+		count := c.count(pos)
+		if count == countMax {
+			err = ErrOverflow
+			continue
+		}
+		c.setCount(pos, count+1)
+	}
+	c.n++
+	return err
+}
+
+// Contains tells if this key was probably in the set at least once.
+func (c *CountingBloom) Contains(s string) bool {
+	h1, h2 := bloomHash(s)
+	for i := uint64(0); i < c.k; i++ {
+		if c.count((h1+i*h2)%c.m) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Delete the element form this set. Deleting a key that was never added
+// but is a false positive corrupts the counters of other keys.
+func (c *CountingBloom) Delete(s string) {
+	if !c.Contains(s) {
+		return
+	}
+	h1, h2 := bloomHash(s)
+	for i := uint64(0); i < c.k; i++ {
+		pos := (h1 + i*h2) % c.m
+		if count := c.count(pos); count != countMax {
+			c.setCount(pos, count-1)
+		}
+	}
+	c.n--
+}
+
+// IsEmpty tells if this set is empty.
+func (c *CountingBloom) IsEmpty() bool { return c.n == 0 }
+
+// Len is the length of this set.
+func (c *CountingBloom) Len() int { return c.n }
+
+// EstimatedFalsePositiveRate is the probability that Contains is true
+// for a key that was never added, given the counters set so far.
+func (c *CountingBloom) EstimatedFalsePositiveRate() float64 {
+	var nonZero uint64
+	for pos := uint64(0); pos < c.m; pos++ {
+		if c.count(pos) != 0 {
+			nonZero++
+		}
+	}
+	return math.Pow(float64(nonZero)/float64(c.m), float64(c.k))
+}
+
+// Capacity is the number of keys this set was sized for.
+func (c *CountingBloom) Capacity() int { return c.capacity }
+
+// FalsePositiveRate is the false positive rate this set was created with.
+func (c *CountingBloom) FalsePositiveRate() float64 { return c.fpRate }
+
+// IsExact is false, a CountingBloom may claim to contain keys it never saw.
+func (c *CountingBloom) IsExact() bool { return false }
@@ -0,0 +1,53 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"testing"
+)
+
+func TestCountingBloom_Empty(t *testing.T) { setTest(t, set.NewCountingBloom(100, 0.001), []string{}) }
+func TestCountingBloom_One(t *testing.T)   { setTest(t, set.NewCountingBloom(100, 0.001), []string{"A"}) }
+func TestCountingBloom_Many(t *testing.T) {
+	setTest(t, set.NewCountingBloom(100, 0.001), []string{"A", "B", "C"})
+}
+func TestCountingBloom_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return set.NewCountingBloom(100, 0.001) })
+}
+
+func TestCountingBloom_FalsePositiveRate(t *testing.T) {
+	c := set.NewCountingBloom(len(web2), 0.01)
+	for _, k := range web2 {
+		c.Add(k)
+	}
+	for _, k := range web2[:len(web2)/2] {
+		c.Delete(k)
+	}
+	fpTest(t, c, web2[:len(web2)/2], 0.01)
+
+	for _, k := range web2[len(web2)/2:] {
+		if !c.Contains(k) {
+			t.Fatalf("should still contain %q", k)
+		}
+	}
+}
+
+func TestCountingBloom_Overflow(t *testing.T) {
+	c := set.NewCountingBloom(100, 0.01)
+	var err error
+	for i := 0; i < 16; i++ {
+		if err = c.TryAdd("A"); i < 15 && err != nil {
+			t.Fatalf("add %d: %v", i, err)
+		}
+	}
+	if err != set.ErrOverflow {
+		t.Fatalf("want %v, got %v", set.ErrOverflow, err)
+	}
+
+	// saturated counters are never cleared
+	for i := 0; i < 16; i++ {
+		c.Delete("A")
+	}
+	if !c.Contains("A") {
+		t.Errorf("saturated counters should keep %q", "A")
+	}
+}
@@ -0,0 +1,172 @@
+package set
+
+import (
+	"container/heap"
+	"github.com/dgryski/go-farm"
+	"math"
+	"sort"
+)
+
+// CountMin estimates how many times each string was added, in a fixed
+// amount of memory, using a count-min sketch. An estimate is never below
+// the true count, and with probability 1-delta it is at most
+// epsilon*Total() above it.
+type CountMin struct {
+	counts []uint64 // depth rows of width counters
+	width  uint64
+	depth  uint64
+	total  uint64
+	eps    float64
+}
+
+// NewCountMin creates a CountMin whose estimates are off by at most
+// epsilon times the total count, with probability 1-delta. Both must be
+// between 0 and 1.
+func NewCountMin(epsilon, delta float64) *CountMin {
+	if !(epsilon > 0 && epsilon < 1 && delta > 0 && delta < 1) {
+		panic("set: count-min epsilon and delta must be between 0 and 1")
+	}
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := uint64(math.Max(1, math.Ceil(math.Log(1/delta))))
+	return &CountMin{
+		counts: make([]uint64, width*depth),
+		width:  width,
+		depth:  depth,
+		eps:    epsilon,
+	}
+}
+
+// Add one occurrence of s.
+func (c *CountMin) Add(s string) {
+	h1, h2 := farm.Hash128([]byte(s))
+	for i := uint64(0); i < c.depth; i++ {
+		c.counts[i*c.width+(h1+i*h2)%c.width]++
+	}
+	c.total++
+}
+
+// Count estimates the number of times s was added.
+func (c *CountMin) Count(s string) uint64 {
+	h1, h2 := farm.Hash128([]byte(s))
+	min := uint64(math.MaxUint64)
+	for i := uint64(0); i < c.depth; i++ {
+		if n := c.counts[i*c.width+(h1+i*h2)%c.width]; n < min {
+			min = n
+		}
+	}
+	return min
+}
+
+// Total is the number of strings added.
+func (c *CountMin) Total() uint64 { return c.total }
+
+// ErrorBound is the most an estimate is likely above the true count.
+func (c *CountMin) ErrorBound() uint64 {
+	return uint64(math.Ceil(c.eps * float64(c.total)))
+}
+
+// TopK tracks the strings added most often using the Space-Saving
+// algorithm, in k counters. When a string is not tracked and the counters
+// are all taken, it replaces the least counted string and inherits its
+// count as an error. Any string added more than Total()/k times is
+// tracked.
+type TopK struct {
+	k       int
+	total   uint64
+	entries topKHeap
+	byKey   map[string]*topKEntry
+}
+
+// TopKEntry is a string tracked by a TopK. Its true count is between
+// Count-Error and Count.
+type TopKEntry struct {
+	Key   string
+	Count uint64
+	Error uint64
+}
+
+type topKEntry struct {
+	TopKEntry
+	index int
+}
+
+// NewTopK creates a TopK of k counters. It panics if k is less than 1.
+func NewTopK(k int) *TopK {
+	if k < 1 {
+		panic("set: top-k needs at least one counter")
+	}
+	return &TopK{
+		k:     k,
+		byKey: make(map[string]*topKEntry, k),
+	}
+}
+
+// Add one occurrence of s.
+func (t *TopK) Add(s string) {
+	t.total++
+	if e, ok := t.byKey[s]; ok {
+		e.Count++
+		heap.Fix(&t.entries, e.index)
+		return
+	}
+	if len(t.entries) < t.k {
+		e := &topKEntry{TopKEntry: TopKEntry{Key: s, Count: 1}}
+		t.byKey[s] = e
+		heap.Push(&t.entries, e)
+		return
+	}
+	e := t.entries[0]
+	delete(t.byKey, e.Key)
+	e.Key, e.Error = s, e.Count
+	e.Count++
+	t.byKey[s] = e
+	heap.Fix(&t.entries, 0)
+}
+
+// Total is the number of strings added.
+func (t *TopK) Total() uint64 { return t.total }
+
+// Top gives the tracked strings, most counted first.
+func (t *TopK) Top() []TopKEntry {
+	top := make([]TopKEntry, len(t.entries))
+	for i, e := range t.entries {
+		top[i] = e.TopKEntry
+	}
+	sort.Sort(byCount(top))
+	return top
+}
+
+type byCount []TopKEntry
+
+func (b byCount) Len() int { return len(b) }
+func (b byCount) Less(i, j int) bool {
+	if b[i].Count != b[j].Count {
+		return b[i].Count > b[j].Count
+	}
+	return b[i].Key < b[j].Key
+}
+func (b byCount) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+
+// topKHeap is a min-heap of the tracked entries by count.
+type topKHeap []*topKEntry
+
+func (h topKHeap) Len() int           { return len(h) }
+func (h topKHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h topKHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *topKHeap) Push(x interface{}) {
+	e := x.(*topKEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *topKHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,78 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"strconv"
+	"testing"
+)
+
+// skewed adds key i about 1000/(i+1) times, so that few keys make most
+// of the count.
+func skewed(add func(string)) map[string]uint64 {
+	want := make(map[string]uint64)
+	for round := 1; round <= 1000; round++ {
+		for i := 0; i < 1000/round; i++ {
+			k := "key" + strconv.Itoa(i)
+			add(k)
+			want[k]++
+		}
+	}
+	return want
+}
+
+func TestCountMin(t *testing.T) {
+	cm := set.NewCountMin(0.001, 0.01)
+	want := skewed(cm.Add)
+
+	var total uint64
+	for k, n := range want {
+		got := cm.Count(k)
+		if got < n {
+			t.Errorf("%q: count %d is below the true count %d", k, got, n)
+		}
+		if got > n+cm.ErrorBound() {
+			t.Errorf("%q: count %d is beyond %d+%d", k, got, n, cm.ErrorBound())
+		}
+		total += n
+	}
+	if cm.Total() != total {
+		t.Errorf("want total %d, got %d", total, cm.Total())
+	}
+	if n := cm.Count("absent"); n > cm.ErrorBound() {
+		t.Errorf("absent key counted %d times", n)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	const k = 50
+	tk := set.NewTopK(k)
+	want := skewed(tk.Add)
+
+	top := tk.Top()
+	if len(top) != k {
+		t.Fatalf("want %d entries, got %d", k, len(top))
+	}
+	for i, e := range top {
+		if i > 0 && e.Count > top[i-1].Count {
+			t.Errorf("entry %d: %q counted more than the one before", i, e.Key)
+		}
+		if n := want[e.Key]; n > e.Count || n < e.Count-e.Error {
+			t.Errorf("%q: true count %d is not within [%d, %d]", e.Key, n, e.Count-e.Error, e.Count)
+		}
+	}
+	// keys with more than Total/k occurrences are always tracked
+	for i := 0; i < 10; i++ {
+		if k := "key" + strconv.Itoa(i); top[i].Key != k {
+			t.Errorf("rank %d: want %q, got %q", i, k, top[i].Key)
+		}
+	}
+}
+
+func TestTopK_NoCounters(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("should panic without counters")
+		}
+	}()
+	set.NewTopK(0)
+}
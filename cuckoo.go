@@ -0,0 +1,207 @@
+package set
+
+import (
+	"github.com/dgryski/go-farm"
+	"math"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	cuckooIsMutable       MutableSet       = NewCuckoo(0, 8)
+	cuckooIsChecked       CheckedSet       = NewCuckoo(0, 8)
+	cuckooIsProbabilistic ProbabilisticSet = NewCuckoo(0, 8)
+)
+
+const (
+	cuckooBucketSize = 4
+	cuckooMaxKicks   = 500
+	cuckooLoadFactor = 0.95
+)
+
+// Cuckoo is a set of string implemented using a cuckoo filter. It only
+// keeps a short fingerprint of each key, in one of two buckets of 4
+// slots, so it may claim to contain keys that were never added. Keys are
+// counted, not deduplicated: adding a key twice takes deleting it twice.
+type Cuckoo struct {
+	slots   packedArray // fingerprints, 0 marks an empty slot
+	buckets uint64      // a power of two
+	n       int
+
+	// when a kick-out chain is too long, the last fingerprint evicted
+	// waits here and the filter is full.
+	victim      uint64
+	victimIndex uint64
+
+	rnd uint64 // picks the slots to evict
+}
+
+// NewCuckoo creates a Cuckoo with room for at least capacity keys, each
+// with a fingerprint of fingerprintBits bits, from 4 to 32.
+func NewCuckoo(capacity int, fingerprintBits uint) *Cuckoo {
+	if fingerprintBits < 4 || fingerprintBits > 32 {
+		panic("set: cuckoo fingerprints must have 4 to 32 bits")
+	}
+	buckets := uint64(1)
+	for float64(buckets*cuckooBucketSize)*cuckooLoadFactor < float64(capacity) {
+		buckets <<= 1
+	}
+	return &Cuckoo{
+		slots:   newPackedArray(buckets*cuckooBucketSize, fingerprintBits),
+		buckets: buckets,
+		rnd:     0x9e3779b97f4a7c15,
+	}
+}
+
+// cuckooHash gives the fingerprint of a key, never 0, and the first of
+// its two buckets.
+func (c *Cuckoo) cuckooHash(s string) (fp, i1 uint64) {
+	h := farm.Hash64([]byte(s))
+	fp = (h >> 32) & c.slots.mask
+	if fp == 0 {
+		fp = 1
+	}
+	return fp, h & (c.buckets - 1)
+}
+
+// altIndex gives the other bucket of a fingerprint, knowing one of them.
+func (c *Cuckoo) altIndex(i, fp uint64) uint64 {
+	return (i ^ (fp * 0x5bd1e995)) & (c.buckets - 1)
+}
+
+func (c *Cuckoo) insert(i, fp uint64) bool {
+	for j := uint64(0); j < cuckooBucketSize; j++ {
+		if c.slots.get(i*cuckooBucketSize+j) == 0 {
+			c.slots.set(i*cuckooBucketSize+j, fp)
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cuckoo) remove(i, fp uint64) bool {
+	for j := uint64(0); j < cuckooBucketSize; j++ {
+		if c.slots.get(i*cuckooBucketSize+j) == fp {
+			c.slots.set(i*cuckooBucketSize+j, 0)
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cuckoo) has(i, fp uint64) bool {
+	for j := uint64(0); j < cuckooBucketSize; j++ {
+		if c.slots.get(i*cuckooBucketSize+j) == fp {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cuckoo) random() uint64 {
+	c.rnd ^= c.rnd << 13
+	c.rnd ^= c.rnd >> 7
+	c.rnd ^= c.rnd << 17
+	return c.rnd
+}
+
+// place puts fp in bucket i or its alternate, evicting fingerprints to
+// their own alternate buckets if both are full. When that takes too many
+// kicks, the last evicted fingerprint becomes the victim.
+func (c *Cuckoo) place(i, fp uint64) {
+	if c.insert(i, fp) || c.insert(c.altIndex(i, fp), fp) {
+		return
+	}
+	if c.random()&1 == 1 {
+		i = c.altIndex(i, fp)
+	}
+	for kick := 0; kick < cuckooMaxKicks; kick++ {
+		slot := i*cuckooBucketSize + c.random()%cuckooBucketSize
+		evicted := c.slots.get(slot)
+		c.slots.set(slot, fp)
+		fp, i = evicted, c.altIndex(i, evicted)
+		if c.insert(i, fp) {
+			return
+		}
+	}
+	c.victim, c.victimIndex = fp, i
+}
+
+// Add the key to the set, panics if the set is full.
+func (c *Cuckoo) Add(s string) {
+	if err := c.TryAdd(s); err != nil {
+		panic(err)
+	}
+}
+
+// TryAdd adds the key to the set, or returns ErrFull if there is no
+// room left for it.
+func (c *Cuckoo) TryAdd(s string) error {
+	if c.victim != 0 {
+		return ErrFull
+	}
+	fp, i := c.cuckooHash(s)
+	c.place(i, fp)
+	c.n++
+	return nil
+}
+
+// Contains tells if this key was probably in the set at least once.
+func (c *Cuckoo) Contains(s string) bool {
+	fp, i1 := c.cuckooHash(s)
+	i2 := c.altIndex(i1, fp)
+	if c.victim == fp && (c.victimIndex == i1 || c.victimIndex == i2) {
+		return true
+	}
+	return c.has(i1, fp) || c.has(i2, fp)
+}
+
+// Delete the element form this set. Deleting a key that was never added
+// but is a false positive removes the fingerprint of another key.
+func (c *Cuckoo) Delete(s string) {
+	fp, i1 := c.cuckooHash(s)
+	i2 := c.altIndex(i1, fp)
+	switch {
+	case c.victim == fp && (c.victimIndex == i1 || c.victimIndex == i2):
+		c.victim = 0
+	case c.remove(i1, fp) || c.remove(i2, fp):
+		if c.victim != 0 {
+			// room was made, give the victim another chance
+			victim, i := c.victim, c.victimIndex
+			c.victim = 0
+			c.place(i, victim)
+		}
+	default:
+		return
+	}
+	c.n--
+}
+
+// IsEmpty tells if this set is empty.
+func (c *Cuckoo) IsEmpty() bool { return c.n == 0 }
+
+// Len is the length of this set.
+func (c *Cuckoo) Len() int { return c.n }
+
+// EstimatedFalsePositiveRate is the probability that Contains is true
+// for a key that was never added, given the slots filled so far: the
+// fingerprints of up to 8 slots are compared.
+func (c *Cuckoo) EstimatedFalsePositiveRate() float64 {
+	return c.falsePositiveRate(float64(c.n) / float64(c.buckets*cuckooBucketSize))
+}
+
+func (c *Cuckoo) falsePositiveRate(load float64) float64 {
+	return 1 - math.Pow(1-1/float64(c.slots.mask), 2*cuckooBucketSize*load)
+}
+
+// Capacity is the number of keys this set can hold before it is likely
+// to be full.
+func (c *Cuckoo) Capacity() int {
+	return int(float64(c.buckets*cuckooBucketSize) * cuckooLoadFactor)
+}
+
+// FalsePositiveRate is the false positive rate of this set once it holds
+// Capacity keys.
+func (c *Cuckoo) FalsePositiveRate() float64 { return c.falsePositiveRate(cuckooLoadFactor) }
+
+// IsExact is false, a Cuckoo may claim to contain keys it never saw.
+func (c *Cuckoo) IsExact() bool { return false }
@@ -0,0 +1,64 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"testing"
+)
+
+func TestCuckoo_Empty(t *testing.T) { setTest(t, set.NewCuckoo(100, 16), []string{}) }
+func TestCuckoo_One(t *testing.T)   { setTest(t, set.NewCuckoo(100, 16), []string{"A"}) }
+func TestCuckoo_Many(t *testing.T)  { setTest(t, set.NewCuckoo(100, 16), []string{"A", "B", "C"}) }
+func TestCuckoo_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return set.NewCuckoo(100, 16) })
+}
+
+func TestCuckoo_FalsePositiveRate(t *testing.T) {
+	for _, bits := range []uint{8, 12, 13} {
+		c := set.NewCuckoo(len(web2), bits)
+		for _, k := range web2 {
+			c.Add(k)
+		}
+		for _, k := range web2[:len(web2)/2] {
+			c.Delete(k)
+		}
+		fpTest(t, c, web2[:len(web2)/2], c.EstimatedFalsePositiveRate())
+
+		for _, k := range web2[len(web2)/2:] {
+			if !c.Contains(k) {
+				t.Fatalf("should still contain %q", k)
+			}
+		}
+	}
+}
+
+func TestCuckoo_Full(t *testing.T) {
+	c := set.NewCuckoo(10, 8)
+
+	var err error
+	var added []string
+	for _, k := range web2 {
+		if err = c.TryAdd(k); err != nil {
+			break
+		}
+		added = append(added, k)
+	}
+	if err != set.ErrFull {
+		t.Fatalf("want %v, got %v", set.ErrFull, err)
+	}
+	if c.Len() != len(added) {
+		t.Fatalf("want %d keys, got %d", len(added), c.Len())
+	}
+	for _, k := range added {
+		if !c.Contains(k) {
+			t.Fatalf("should contain %q", k)
+		}
+	}
+
+	// deleting makes room again
+	for _, k := range added {
+		c.Delete(k)
+	}
+	if err := c.TryAdd("A"); err != nil {
+		t.Fatalf("after deletions: %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package set
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	doubleArrayIsPrefix  PrefixSet  = NewDoubleArray(NewGoMap(0))
+	doubleArrayIsChecked CheckedSet = NewDoubleArray(NewGoMap(0))
+)
+
+const (
+	daFree     = -1
+	daTerminal = 0 // code of the transition marking the end of a key
+)
+
+// DoubleArray is a static set of string implemented using a double-array
+// trie. Transitions are stored in two flat arrays: the child of state s
+// on byte c is t = base[s]+c+1, valid only if check[t] == s.
+type DoubleArray struct {
+	base  []int32
+	check []int32
+	count int
+
+	firstFree int32 // only used while building
+}
+
+// NewDoubleArray builds a DoubleArray holding the keys of s.
+func NewDoubleArray(s ListSet) *DoubleArray {
+	keys := s.Keys()
+	sort.Strings(keys)
+
+	da := &DoubleArray{count: len(keys), firstFree: 1}
+	da.resize(256)
+	da.check[0] = 0
+	if len(keys) != 0 {
+		da.insert(0, keys, 0)
+	}
+	da.trim()
+	return da
+}
+
+func (da *DoubleArray) resize(n int) {
+	base := make([]int32, n)
+	check := make([]int32, n)
+	copy(base, da.base)
+	copy(check, da.check)
+	for i := len(da.check); i < n; i++ {
+		check[i] = daFree
+	}
+	da.base, da.check = base, check
+}
+
+func (da *DoubleArray) trim() {
+	last := len(da.check) - 1
+	for last > 0 && da.check[last] == daFree {
+		last--
+	}
+	da.resize(last + 1)
+}
+
+type daEdge struct {
+	code   int32
+	lo, hi int
+}
+
+// insert places the children of state s, which are the keys sharing
+// their first depth bytes, then recurses into each of them.
+func (da *DoubleArray) insert(s int32, keys []string, depth int) {
+	var edges []daEdge
+	for i := 0; i < len(keys); {
+		code := int32(daTerminal)
+		if len(keys[i]) > depth {
+			code = int32(keys[i][depth]) + 1
+		}
+		j := i + 1
+		for j < len(keys) && len(keys[j]) > depth && int32(keys[j][depth])+1 == code {
+			j++
+		}
+		edges = append(edges, daEdge{code: code, lo: i, hi: j})
+		i = j
+	}
+
+	b := da.findBase(edges)
+	da.base[s] = b
+	for _, e := range edges {
+		da.check[b+e.code] = s
+	}
+	for int(da.firstFree) < len(da.check) && da.check[da.firstFree] != daFree {
+		da.firstFree++
+	}
+
+	for _, e := range edges {
+		if e.code != daTerminal {
+			da.insert(b+e.code, keys[e.lo:e.hi], depth+1)
+		}
+	}
+}
+
+// findBase finds the first base for which every edge lands on a free
+// slot, growing the arrays if needed.
+func (da *DoubleArray) findBase(edges []daEdge) int32 {
+	first, last := edges[0].code, edges[len(edges)-1].code
+	for pos := da.firstFree; ; pos++ {
+		b := pos - first
+		if b < 1 {
+			continue
+		}
+		if int(b+last) >= len(da.check) {
+			da.resize(2*len(da.check) + int(last))
+		}
+		if da.check[pos] != daFree {
+			continue
+		}
+		fits := true
+		for _, e := range edges[1:] {
+			if da.check[b+e.code] != daFree {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return b
+		}
+	}
+}
+
+func (da *DoubleArray) child(s, code int32) (int32, bool) {
+	b := da.base[s]
+	if b < 1 {
+		return 0, false
+	}
+	t := b + code
+	if t < 0 || int(t) >= len(da.check) || da.check[t] != s {
+		return 0, false
+	}
+	return t, true
+}
+
+func (da *DoubleArray) walk(key string) (int32, bool) {
+	s := int32(0)
+	for i := 0; i < len(key); i++ {
+		t, ok := da.child(s, int32(key[i])+1)
+		if !ok {
+			return 0, false
+		}
+		s = t
+	}
+	return s, true
+}
+
+// Add is not supported, a DoubleArray is immutable.
+func (da *DoubleArray) Add(s string) { panic(ErrImmutable) }
+
+// TryAdd always returns ErrImmutable.
+func (da *DoubleArray) TryAdd(s string) error { return ErrImmutable }
+
+// Contains tells if this key is in the set.
+func (da *DoubleArray) Contains(s string) bool {
+	state, ok := da.walk(s)
+	if !ok {
+		return false
+	}
+	_, ok = da.child(state, daTerminal)
+	return ok
+}
+
+// IsEmpty tells if this set is empty.
+func (da *DoubleArray) IsEmpty() bool { return da.count == 0 }
+
+// Len is the length of this set.
+func (da *DoubleArray) Len() int { return da.count }
+
+// Keys gives all the keys in this DoubleArray, in sorted order.
+func (da *DoubleArray) Keys() []string { return da.PrefixKeys("") }
+
+// PrefixKeys gives the keys starting with prefix, in sorted order.
+func (da *DoubleArray) PrefixKeys(prefix string) []string {
+	s, ok := da.walk(prefix)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	da.collect(s, []byte(prefix), &keys)
+	return keys
+}
+
+func (da *DoubleArray) collect(s int32, key []byte, keys *[]string) {
+	if _, ok := da.child(s, daTerminal); ok {
+		*keys = append(*keys, string(key))
+	}
+	for code := int32(1); code <= 256; code++ {
+		if t, ok := da.child(s, code); ok {
+			da.collect(t, append(key, byte(code-1)), keys)
+		}
+	}
+}
+
+// MarshalBinary encodes the DoubleArray as a flat byte slice: the key
+// count and the number of states, followed by the base and check arrays,
+// all little endian.
+func (da *DoubleArray) MarshalBinary() ([]byte, error) {
+	n := len(da.base)
+	buf := make([]byte, 8+8*n)
+	binary.LittleEndian.PutUint32(buf[0:], uint32(da.count))
+	binary.LittleEndian.PutUint32(buf[4:], uint32(n))
+	for i := 0; i < n; i++ {
+		binary.LittleEndian.PutUint32(buf[8+4*i:], uint32(da.base[i]))
+		binary.LittleEndian.PutUint32(buf[8+4*n+4*i:], uint32(da.check[i]))
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a DoubleArray encoded by MarshalBinary.
+func (da *DoubleArray) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return ErrMalformed
+	}
+	count := int(binary.LittleEndian.Uint32(data[0:]))
+	n := int(binary.LittleEndian.Uint32(data[4:]))
+	if n == 0 || len(data) != 8+8*n {
+		return ErrMalformed
+	}
+	base := make([]int32, n)
+	check := make([]int32, n)
+	for i := 0; i < n; i++ {
+		base[i] = int32(binary.LittleEndian.Uint32(data[8+4*i:]))
+		check[i] = int32(binary.LittleEndian.Uint32(data[8+4*n+4*i:]))
+	}
+	da.base, da.check, da.count = base, check, count
+	return nil
+}
@@ -0,0 +1,44 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"testing"
+)
+
+func buildDoubleArray(s set.ListSet) set.Set { return set.NewDoubleArray(s) }
+
+func TestDoubleArray_Empty(t *testing.T) { staticTest(t, buildDoubleArray, []string{}) }
+func TestDoubleArray_One(t *testing.T)   { staticTest(t, buildDoubleArray, []string{"A"}) }
+func TestDoubleArray_Many(t *testing.T)  { staticTest(t, buildDoubleArray, []string{"A", "B", "C"}) }
+func TestDoubleArray_Words(t *testing.T) { staticTest(t, buildDoubleArray, setA.Keys()) }
+func TestDoubleArray_EmptyKey(t *testing.T) {
+	staticTest(t, buildDoubleArray, []string{"", "a", "ab"})
+}
+
+func TestDoubleArray_Prefix(t *testing.T) {
+	da := set.NewDoubleArray(setFromList([]string{"aba", "abac", "abacus", "abb", "b"}))
+	prefixTest(t, da, "", []string{"aba", "abac", "abacus", "abb", "b"})
+	prefixTest(t, da, "aba", []string{"aba", "abac", "abacus"})
+	prefixTest(t, da, "abacu", []string{"abacus"})
+	prefixTest(t, da, "abc", []string{})
+}
+
+func TestDoubleArray_MarshalBinary(t *testing.T) {
+	want := setA.Keys()
+	data, err := set.NewDoubleArray(setFromList(want)).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	staticTest(t, func(set.ListSet) set.Set {
+		da := &set.DoubleArray{}
+		if err := da.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		return da
+	}, want)
+
+	if err := (&set.DoubleArray{}).UnmarshalBinary(data[:len(data)-1]); err != set.ErrMalformed {
+		t.Errorf("want %v on truncated input, got %v", set.ErrMalformed, err)
+	}
+}
@@ -0,0 +1,179 @@
+package set
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	frontCodedIsOrdered OrderedSet = NewFrontCoded(NewGoMap(0), 16)
+	frontCodedIsChecked CheckedSet = NewFrontCoded(NewGoMap(0), 16)
+)
+
+// FrontCoded is a static set of string implemented using a front coded
+// dictionary. Sorted keys are grouped in buckets of k: the first key of
+// a bucket is stored whole, and each following key as the length of the
+// prefix it shares with its predecessor and the remaining suffix.
+type FrontCoded struct {
+	data    []byte
+	buckets []uint32 // offset of each bucket in data
+	k       int
+	count   int
+}
+
+// NewFrontCoded builds a FrontCoded holding the keys of s, in buckets of
+// k keys. Larger buckets compress better but make lookups scan longer.
+func NewFrontCoded(s ListSet, k int) *FrontCoded {
+	if k < 1 {
+		k = 1
+	}
+	keys := s.Keys()
+	sort.Strings(keys)
+
+	fc := &FrontCoded{k: k, count: len(keys)}
+	var tmp [binary.MaxVarintLen64]byte
+	putUvarint := func(v int) {
+		n := binary.PutUvarint(tmp[:], uint64(v))
+		fc.data = append(fc.data, tmp[:n]...)
+	}
+
+	for i, key := range keys {
+		if i%k == 0 {
+			fc.buckets = append(fc.buckets, uint32(len(fc.data)))
+			putUvarint(len(key))
+			fc.data = append(fc.data, key...)
+			continue
+		}
+		shared := commonPrefix(keys[i-1], key)
+		putUvarint(shared)
+		putUvarint(len(key) - shared)
+		fc.data = append(fc.data, key[shared:]...)
+	}
+	return fc
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+func (fc *FrontCoded) uvarint(off int) (int, int) {
+	v, n := binary.Uvarint(fc.data[off:])
+	return int(v), off + n
+}
+
+// head gives the first key of bucket b, without copying it.
+func (fc *FrontCoded) head(b int) []byte {
+	l, off := fc.uvarint(int(fc.buckets[b]))
+	return fc.data[off : off+l]
+}
+
+// scan decodes the keys of bucket b in order, until fn returns false.
+// The key given to fn is only valid until fn returns.
+func (fc *FrontCoded) scan(b int, fn func(i int, key []byte) bool) {
+	l, off := fc.uvarint(int(fc.buckets[b]))
+	key := append([]byte(nil), fc.data[off:off+l]...)
+	off += l
+	end := fc.count - b*fc.k
+	if end > fc.k {
+		end = fc.k
+	}
+	for i := 0; i < end; i++ {
+		if i != 0 {
+			var shared, suffix int
+			shared, off = fc.uvarint(off)
+			suffix, off = fc.uvarint(off)
+			key = append(key[:shared], fc.data[off:off+suffix]...)
+			off += suffix
+		}
+		if !fn(i, key) {
+			return
+		}
+	}
+}
+
+// bucketOf finds the last bucket whose head is not after s, or -1.
+func (fc *FrontCoded) bucketOf(s []byte) int {
+	return sort.Search(len(fc.buckets), func(b int) bool {
+		return bytes.Compare(fc.head(b), s) > 0
+	}) - 1
+}
+
+// Add is not supported, a FrontCoded is immutable.
+func (fc *FrontCoded) Add(s string) { panic(ErrImmutable) }
+
+// TryAdd always returns ErrImmutable.
+func (fc *FrontCoded) TryAdd(s string) error { return ErrImmutable }
+
+// Contains tells if this key is in the set.
+func (fc *FrontCoded) Contains(s string) bool {
+	key := []byte(s)
+	b := fc.bucketOf(key)
+	if b < 0 {
+		return false
+	}
+	found := false
+	fc.scan(b, func(_ int, k []byte) bool {
+		c := bytes.Compare(k, key)
+		found = c == 0
+		return c < 0
+	})
+	return found
+}
+
+// IsEmpty tells if this set is empty.
+func (fc *FrontCoded) IsEmpty() bool { return fc.count == 0 }
+
+// Len is the length of this set.
+func (fc *FrontCoded) Len() int { return fc.count }
+
+// Keys gives all the keys in this FrontCoded, in sorted order.
+func (fc *FrontCoded) Keys() []string {
+	keys := make([]string, 0, fc.count)
+	for b := range fc.buckets {
+		fc.scan(b, func(_ int, k []byte) bool {
+			keys = append(keys, string(k))
+			return true
+		})
+	}
+	return keys
+}
+
+// Rank tells how many keys of the set sort before s.
+func (fc *FrontCoded) Rank(s string) int {
+	key := []byte(s)
+	b := fc.bucketOf(key)
+	if b < 0 {
+		return 0
+	}
+	rank := b * fc.k
+	fc.scan(b, func(_ int, k []byte) bool {
+		if bytes.Compare(k, key) >= 0 {
+			return false
+		}
+		rank++
+		return true
+	})
+	return rank
+}
+
+// Select gives the key of rank i, if there is one.
+func (fc *FrontCoded) Select(i int) (string, bool) {
+	if i < 0 || i >= fc.count {
+		return "", false
+	}
+	var key string
+	fc.scan(i/fc.k, func(j int, k []byte) bool {
+		if j < i%fc.k {
+			return true
+		}
+		key = string(k)
+		return false
+	})
+	return key, true
+}
@@ -0,0 +1,29 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"testing"
+)
+
+func buildFrontCoded(k int) func(set.ListSet) set.Set {
+	return func(s set.ListSet) set.Set { return set.NewFrontCoded(s, k) }
+}
+
+func TestFrontCoded_Empty(t *testing.T) { staticTest(t, buildFrontCoded(16), []string{}) }
+func TestFrontCoded_One(t *testing.T)   { staticTest(t, buildFrontCoded(16), []string{"A"}) }
+func TestFrontCoded_Many(t *testing.T)  { staticTest(t, buildFrontCoded(16), []string{"A", "B", "C"}) }
+func TestFrontCoded_Words(t *testing.T) {
+	for _, k := range []int{1, 2, 16, 1000} {
+		staticTest(t, buildFrontCoded(k), setA.Keys())
+	}
+}
+func TestFrontCoded_EmptyKey(t *testing.T) {
+	staticTest(t, buildFrontCoded(2), []string{"", "a", "ab"})
+}
+
+func TestFrontCoded_Ordered(t *testing.T) {
+	for _, k := range []int{1, 3, 16} {
+		want := setA.Keys()
+		orderedTest(t, set.NewFrontCoded(setFromList(want), k), want)
+	}
+}
@@ -0,0 +1,267 @@
+package set
+
+import (
+	"encoding/binary"
+	"github.com/dgryski/go-farm"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	golombSetIsChecked       CheckedSet       = NewGolombSet(NewGoMap(0), 0.01)
+	golombSetIsProbabilistic ProbabilisticSet = NewGolombSet(NewGoMap(0), 0.01)
+)
+
+// golombIndexInterval is the number of values between two entries of the
+// index that lets Contains skip most of the stream.
+const golombIndexInterval = 128
+
+// GolombSet is a static set of string implemented using a Golomb-Rice
+// coded set. Keys are hashed to a range of n/p values, sorted, and the
+// differences between consecutive hashes are Rice coded: with a false
+// positive rate of p, it takes about log2(1/p)+1.5 bits per key, where
+// a Bloom filter takes 1.44*log2(1/p).
+type GolombSet struct {
+	data  []byte // the Rice coded deltas, most significant bit first
+	k     uint   // Rice parameter, the range is n<<k
+	n     int
+	index []golombIndexEntry
+}
+
+type golombIndexEntry struct {
+	value uint64 // the value of every golombIndexInterval-th key
+	pos   uint64 // the bit following its code
+}
+
+// NewGolombSet builds a GolombSet holding the keys of s, with a false
+// positive rate of at most fpRate.
+func NewGolombSet(s ListSet, fpRate float64) *GolombSet {
+	if !(fpRate > 0 && fpRate < 1) {
+		panic("set: golomb set false positive rate must be between 0 and 1")
+	}
+	k := uint(math.Ceil(math.Log2(1 / fpRate)))
+	if k > 32 {
+		k = 32
+	}
+	keys := s.Keys()
+	g := &GolombSet{k: k, n: len(keys)}
+
+	values := make([]uint64, len(keys))
+	for i, key := range keys {
+		values[i] = g.hash(key)
+	}
+	sort.Sort(uint64Slice(values))
+
+	var w bitWriter
+	prev := uint64(0)
+	for _, v := range values {
+		w.writeRice(v-prev, k)
+		prev = v
+	}
+	g.data = w.buf
+	g.buildIndex()
+	return g
+}
+
+// hash maps a key to the range [0, n<<k).
+func (g *GolombSet) hash(s string) uint64 {
+	hi, _ := bits.Mul64(farm.Hash64([]byte(s)), uint64(g.n)<<g.k)
+	return hi
+}
+
+func (g *GolombSet) buildIndex() {
+	g.index = make([]golombIndexEntry, 0, g.n/golombIndexInterval+1)
+	r := bitReader{buf: g.data}
+	v := uint64(0)
+	for i := 0; i < g.n; i++ {
+		v += r.readRice(g.k)
+		if i%golombIndexInterval == 0 {
+			g.index = append(g.index, golombIndexEntry{value: v, pos: r.pos})
+		}
+	}
+}
+
+// Add is not supported, a GolombSet is immutable.
+func (g *GolombSet) Add(s string) { panic(ErrImmutable) }
+
+// TryAdd always returns ErrImmutable.
+func (g *GolombSet) TryAdd(s string) error { return ErrImmutable }
+
+// Contains tells if this key was probably in the set.
+func (g *GolombSet) Contains(s string) bool {
+	if g.n == 0 {
+		return false
+	}
+	h := g.hash(s)
+	j := sort.Search(len(g.index), func(j int) bool { return g.index[j].value > h }) - 1
+	if j < 0 {
+		return false
+	}
+	r := bitReader{buf: g.data, pos: g.index[j].pos}
+	v := g.index[j].value
+	for i := j*golombIndexInterval + 1; v < h && i < g.n; i++ {
+		v += r.readRice(g.k)
+	}
+	return v == h
+}
+
+// Matches tells for each of the keys if it is probably in the set, in a
+// single pass over the set.
+func (g *GolombSet) Matches(keys []string) []bool {
+	found := make([]bool, len(keys))
+	if g.n == 0 {
+		return found
+	}
+	queries := make([]golombQuery, len(keys))
+	for i, k := range keys {
+		queries[i] = golombQuery{hash: g.hash(k), i: i}
+	}
+	sort.Sort(byHash(queries))
+
+	r := bitReader{buf: g.data}
+	v := r.readRice(g.k)
+	decoded := 1
+	for _, q := range queries {
+		for v < q.hash && decoded < g.n {
+			v += r.readRice(g.k)
+			decoded++
+		}
+		found[q.i] = v == q.hash
+	}
+	return found
+}
+
+type golombQuery struct {
+	hash uint64
+	i    int
+}
+
+type byHash []golombQuery
+
+func (b byHash) Len() int           { return len(b) }
+func (b byHash) Less(i, j int) bool { return b[i].hash < b[j].hash }
+func (b byHash) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// IsEmpty tells if this set is empty.
+func (g *GolombSet) IsEmpty() bool { return g.n == 0 }
+
+// Len is the length of this set.
+func (g *GolombSet) Len() int { return g.n }
+
+// EstimatedFalsePositiveRate is the probability that Contains is true
+// for a key that was never added.
+func (g *GolombSet) EstimatedFalsePositiveRate() float64 {
+	return 1 / float64(uint64(1)<<g.k)
+}
+
+// Capacity is the length of this set, it was built with all its keys.
+func (g *GolombSet) Capacity() int { return g.n }
+
+// FalsePositiveRate is the probability that Contains is true for a key
+// that was never added.
+func (g *GolombSet) FalsePositiveRate() float64 { return g.EstimatedFalsePositiveRate() }
+
+// IsExact is false, a GolombSet may claim to contain keys it never saw.
+func (g *GolombSet) IsExact() bool { return false }
+
+// MarshalBinary encodes the set: the number of keys as a uvarint, the
+// Rice parameter in a byte, then the coded deltas.
+func (g *GolombSet) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, binary.MaxVarintLen64+1, binary.MaxVarintLen64+1+len(g.data))
+	n := binary.PutUvarint(buf, uint64(g.n))
+	buf[n] = byte(g.k)
+	return append(buf[:n+1], g.data...), nil
+}
+
+// UnmarshalBinary decodes a set encoded by MarshalBinary.
+func (g *GolombSet) UnmarshalBinary(data []byte) error {
+	count, n := binary.Uvarint(data)
+	if n <= 0 || len(data) == n || data[n] < 1 || data[n] > 32 || count > math.MaxInt32 {
+		return ErrMalformed
+	}
+	k := uint(data[n])
+	data = data[n+1:]
+	// every key takes at least k+1 bits
+	if count*uint64(k+1) > uint64(len(data))*8 {
+		return ErrMalformed
+	}
+	out := GolombSet{data: append([]byte(nil), data...), k: k, n: int(count)}
+	if !out.valid() {
+		return ErrMalformed
+	}
+	out.buildIndex()
+	*g = out
+	return nil
+}
+
+// valid tells if the stream holds n codes, none running out of the data.
+func (g *GolombSet) valid() bool {
+	r := bitReader{buf: g.data}
+	end := uint64(len(g.data)) * 8
+	for i := 0; i < g.n; i++ {
+		for {
+			if r.pos == end {
+				return false
+			}
+			if r.readBit() == 0 {
+				break
+			}
+		}
+		if r.pos+uint64(g.k) > end {
+			return false
+		}
+		r.pos += uint64(g.k)
+	}
+	return true
+}
+
+// bitWriter appends bits to a buffer, most significant bit first.
+type bitWriter struct {
+	buf  []byte
+	nbit uint64
+}
+
+func (w *bitWriter) writeBit(b uint64) {
+	if w.nbit%8 == 0 {
+		w.buf = append(w.buf, 0)
+	}
+	w.buf[len(w.buf)-1] |= byte(b) << (7 - w.nbit%8)
+	w.nbit++
+}
+
+// writeRice writes the quotient of v by 2^k in unary, then the k low
+// bits of v.
+func (w *bitWriter) writeRice(v uint64, k uint) {
+	for q := v >> k; q > 0; q-- {
+		w.writeBit(1)
+	}
+	w.writeBit(0)
+	for i := int(k) - 1; i >= 0; i-- {
+		w.writeBit(v >> uint(i) & 1)
+	}
+}
+
+type bitReader struct {
+	buf []byte
+	pos uint64
+}
+
+func (r *bitReader) readBit() uint64 {
+	b := r.buf[r.pos/8] >> (7 - r.pos%8) & 1
+	r.pos++
+	return uint64(b)
+}
+
+func (r *bitReader) readRice(k uint) uint64 {
+	q := uint64(0)
+	for r.readBit() == 1 {
+		q++
+	}
+	v := q << k
+	for i := int(k) - 1; i >= 0; i-- {
+		v |= r.readBit() << uint(i)
+	}
+	return v
+}
@@ -0,0 +1,64 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"strconv"
+	"testing"
+)
+
+func buildGolomb(s set.ListSet) set.Set { return set.NewGolombSet(s, 0.01) }
+
+func TestGolombSet_Empty(t *testing.T) { staticTest(t, buildGolomb, []string{}) }
+func TestGolombSet_One(t *testing.T)   { staticTest(t, buildGolomb, []string{"A"}) }
+func TestGolombSet_Many(t *testing.T)  { staticTest(t, buildGolomb, []string{"A", "B", "C"}) }
+func TestGolombSet_Web2(t *testing.T)  { staticTest(t, buildGolomb, web2) }
+
+func TestGolombSet_Matches(t *testing.T) {
+	keys := set.NewGoMap(0)
+	for i := 0; i < 1000; i++ {
+		keys.Add("key" + strconv.Itoa(i))
+	}
+	g := set.NewGolombSet(keys, 0.001)
+
+	queries := []string{"absent"}
+	for i := 990; i < 1010; i++ {
+		queries = append(queries, "key"+strconv.Itoa(i))
+	}
+	for i, found := range g.Matches(queries) {
+		if found != g.Contains(queries[i]) {
+			t.Errorf("%q: Matches says %v, Contains disagrees", queries[i], found)
+		}
+		if i > 0 && i <= 10 && !found {
+			t.Errorf("should contain %q", queries[i])
+		}
+	}
+}
+
+func TestGolombSet_Binary(t *testing.T) {
+	g := set.NewGolombSet(setFromList(web2), 0.001)
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// about log2(1/p)+1.5 bits per key
+	if max := len(web2) * 12 / 8; len(data) > max+16 {
+		t.Errorf("%d bytes for %d keys, want at most %d", len(data), len(web2), max)
+	}
+
+	var got set.GolombSet
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range web2 {
+		if !got.Contains(k) {
+			t.Fatalf("should contain %q", k)
+		}
+	}
+	if got.Len() != g.Len() {
+		t.Errorf("want length %d, got %d", g.Len(), got.Len())
+	}
+
+	if err := got.UnmarshalBinary(data[:len(data)/2]); err != set.ErrMalformed {
+		t.Errorf("truncated: want %v, got %v", set.ErrMalformed, err)
+	}
+}
@@ -11,12 +11,16 @@ var (
 
 // NewSpooky128 is a Hash128 with spooky hash for hasher.
 func NewSpooky128(n int, collidePanics bool) *Hash128 {
-	return NewHashFunc128(n, func(b []byte) (lo, hi uint64) { spooky.Hash128(b, &lo, &hi); return }, collidePanics)
+	h := NewHashFunc128(n, func(b []byte) (lo, hi uint64) { spooky.Hash128(b, &lo, &hi); return }, collidePanics)
+	h.name = "spooky"
+	return h
 }
 
 // NewFarm128 is a Hash128 with farmhash for hasher.
 func NewFarm128(n int, collidePanics bool) *Hash128 {
-	return NewHashFunc128(n, farm.Hash128, collidePanics)
+	h := NewHashFunc128(n, farm.Hash128, collidePanics)
+	h.name = "farm"
+	return h
 }
 
 type uint128 struct{ lo, hi uint64 }
@@ -26,6 +30,7 @@ type Hash128 struct {
 	m             map[uint128]struct{}
 	collidePanics bool
 	fh128         func(s []byte) (uint64, uint64)
+	name          string // of the registered hasher, if known
 }
 
 // NewHashFunc128 creates a hash set using a hash128 hasher func.
@@ -37,6 +42,10 @@ func NewHashFunc128(n int, fh128 func(s []byte) (uint64, uint64), collidePanics
 	}
 }
 
+// Hasher is the name under which the hasher of this set is registered,
+// or "" if it was not created from a registered hasher.
+func (m *Hash128) Hasher() string { return m.name }
+
 func (m *Hash128) get128Block(s string) uint128 {
 	lo, hi := m.fh128([]byte(s))
 	return uint128{lo: lo, hi: hi}
@@ -65,3 +74,88 @@ func (m *Hash128) Len() int { return len(m.m) }
 
 // Delete the element form this set.
 func (m *Hash128) Delete(s string) { delete(m.m, m.get128Block(s)) }
+
+// derive is an empty set with the hasher of m.
+func (m *Hash128) derive(n int) *Hash128 {
+	out := NewHashFunc128(n, m.fh128, m.collidePanics)
+	out.name = m.name
+	return out
+}
+
+// compatible tells if the digests of both sets come from the same
+// hasher. Sets without a registered hasher name are never compatible,
+// their hasher funcs can't be told apart.
+func (m *Hash128) compatible(other *Hash128) bool {
+	return m.name != "" && m.name == other.name
+}
+
+// Union is a new set of the digests in m or in other, or ErrIncompatible
+// if their hashers differ. It works on digests alone, the keys are not
+// needed.
+func (m *Hash128) Union(other *Hash128) (*Hash128, error) {
+	if !m.compatible(other) {
+		return nil, ErrIncompatible
+	}
+	out := m.derive(len(m.m) + len(other.m))
+	for d := range m.m {
+		out.m[d] = q
+	}
+	for d := range other.m {
+		out.m[d] = q
+	}
+	return out, nil
+}
+
+// Intersect is a new set of the digests in both m and other, or
+// ErrIncompatible if their hashers differ.
+func (m *Hash128) Intersect(other *Hash128) (*Hash128, error) {
+	if !m.compatible(other) {
+		return nil, ErrIncompatible
+	}
+	small, large := m, other
+	if len(small.m) > len(large.m) {
+		small, large = large, small
+	}
+	out := m.derive(len(small.m))
+	for d := range small.m {
+		if _, ok := large.m[d]; ok {
+			out.m[d] = q
+		}
+	}
+	return out, nil
+}
+
+// Difference is a new set of the digests in m but not in other, or
+// ErrIncompatible if their hashers differ.
+func (m *Hash128) Difference(other *Hash128) (*Hash128, error) {
+	if !m.compatible(other) {
+		return nil, ErrIncompatible
+	}
+	out := m.derive(len(m.m))
+	for d := range m.m {
+		if _, ok := other.m[d]; !ok {
+			out.m[d] = q
+		}
+	}
+	return out, nil
+}
+
+// XOR is a new set of the digests in either m or other but not both, or
+// ErrIncompatible if their hashers differ.
+func (m *Hash128) XOR(other *Hash128) (*Hash128, error) {
+	if !m.compatible(other) {
+		return nil, ErrIncompatible
+	}
+	out := m.derive(len(m.m) + len(other.m))
+	for d := range m.m {
+		if _, ok := other.m[d]; !ok {
+			out.m[d] = q
+		}
+	}
+	for d := range other.m {
+		if _, ok := m.m[d]; !ok {
+			out.m[d] = q
+		}
+	}
+	return out, nil
+}
@@ -50,3 +50,33 @@ func checkHash128SetOp(t *testing.T, n int) {
 		checkSetOp(t, func() set.Set { return hashset(n, false) })
 	}
 }
+
+func TestHash128_DigestOperations(t *testing.T) {
+	fill := func(h *set.Hash128, keys []string) *set.Hash128 {
+		for _, k := range keys {
+			h.Add(k)
+		}
+		return h
+	}
+	for name, hashset := range hash128table {
+		t.Logf("-- Hash128: %q --", name)
+		a := fill(hashset(0, true), []string{"A", "B", "C"})
+		b := fill(hashset(0, true), []string{"B", "C", "D"})
+
+		union, err := a.Union(b)
+		checkDigestOp(t, union, err, []string{"A", "B", "C", "D"})
+		inter, err := a.Intersect(b)
+		checkDigestOp(t, inter, err, []string{"B", "C"})
+		diff, err := a.Difference(b)
+		checkDigestOp(t, diff, err, []string{"A"})
+		xor, err := a.XOR(b)
+		checkDigestOp(t, xor, err, []string{"A", "D"})
+	}
+}
+
+func TestHash128_DigestOperationsIncompatible(t *testing.T) {
+	a, b := set.NewFarm128(0, true), set.NewSpooky128(0, true)
+	if _, err := a.XOR(b); err != set.ErrIncompatible {
+		t.Fatalf("want %v combining farm and spooky digests, got %v", set.ErrIncompatible, err)
+	}
+}
@@ -7,16 +7,31 @@ import (
 )
 
 var (
-	hash64IsMutable MutableSet = NewHash64(0, nil, true)
+	hash64IsMutable          MutableSet = NewHash64(0, nil, true)
+	robinHoodHash64IsMutable MutableSet = NewFarmRobinHood64(0, true)
 )
 
 // NewSpooky64 is a Hash64 with spooky hash for hasher.
 func NewSpooky64(n int, collidePanics bool) *Hash64 {
-	return NewHashFunc64(n, spooky.Hash64, collidePanics)
+	h := NewHashFunc64(n, spooky.Hash64, collidePanics)
+	h.name = "spooky"
+	return h
 }
 
 // NewFarm64 is a Hash64 with farmhash for hasher.
-func NewFarm64(n int, collidePanics bool) *Hash64 { return NewHashFunc64(n, farm.Hash64, collidePanics) }
+func NewFarm64(n int, collidePanics bool) *Hash64 {
+	h := NewHashFunc64(n, farm.Hash64, collidePanics)
+	h.name = "farm"
+	return h
+}
+
+// NewFarmRobinHood64 is a Hash64 with farmhash for hasher, holding its
+// digests in a Robin Hood table.
+func NewFarmRobinHood64(n int, collidePanics bool) *Hash64 {
+	h := NewRobinHoodFunc64(n, farm.Hash64, collidePanics)
+	h.name = "farm"
+	return h
+}
 
 func fromHash64(h64 hash.Hash64) func([]byte) uint64 {
 	return func(b []byte) uint64 {
@@ -28,9 +43,10 @@ func fromHash64(h64 hash.Hash64) func([]byte) uint64 {
 
 // Hash64 is a hash based set, using a 64 bits hasher.
 type Hash64 struct {
-	m             map[uint64]struct{}
+	store         digestStore
 	collidePanics bool
 	fh64          func(s []byte) uint64
+	name          string // of the registered hasher, if known
 }
 
 // NewHash64 creates a hash set using a hash64 hasher.
@@ -41,12 +57,27 @@ func NewHash64(n int, h hash.Hash64, collidePanics bool) *Hash64 {
 // NewHashFunc64 creates a hash set using a hash64 hasher func.
 func NewHashFunc64(n int, fh64 func(s []byte) uint64, collidePanics bool) *Hash64 {
 	return &Hash64{
-		m:             make(map[uint64]struct{}, n),
+		store:         make(mapStore, n),
+		fh64:          fh64,
+		collidePanics: collidePanics,
+	}
+}
+
+// NewRobinHoodFunc64 creates a hash set using a hash64 hasher func, with
+// its digests in a flat open addressing table instead of a Go map: the
+// runtime does not hash the digests again, and each one takes 8 bytes.
+func NewRobinHoodFunc64(n int, fh64 func(s []byte) uint64, collidePanics bool) *Hash64 {
+	return &Hash64{
+		store:         newRobinHood(n),
 		fh64:          fh64,
 		collidePanics: collidePanics,
 	}
 }
 
+// Hasher is the name under which the hasher of this set is registered,
+// or "" if it was not created from a registered hasher.
+func (m *Hash64) Hasher() string { return m.name }
+
 func (m *Hash64) get64Block(s string) uint64 {
 	return m.fh64([]byte(s))
 }
@@ -54,23 +85,103 @@ func (m *Hash64) get64Block(s string) uint64 {
 // Add the key to the set.
 func (m *Hash64) Add(s string) {
 	block := m.get64Block(s)
-	if m.collidePanics {
-		_, ok := m.m[block]
-		if ok {
-			panic("Collision with '" + s + "'")
-		}
+	if !m.store.add(block) && m.collidePanics {
+		panic("Collision with '" + s + "'")
 	}
-	m.m[block] = q
 }
 
 // Contains tells if this key was in the set at least once.
-func (m *Hash64) Contains(s string) bool { _, ok := m.m[m.get64Block(s)]; return ok }
+func (m *Hash64) Contains(s string) bool { return m.store.has(m.get64Block(s)) }
 
 // IsEmpty tells if this set is empty.
-func (m *Hash64) IsEmpty() bool { return len(m.m) == 0 }
+func (m *Hash64) IsEmpty() bool { return m.store.len() == 0 }
 
 // Len is the length of this set.
-func (m *Hash64) Len() int { return len(m.m) }
+func (m *Hash64) Len() int { return m.store.len() }
 
 // Delete the element form this set.
-func (m *Hash64) Delete(s string) { delete(m.m, m.get64Block(s)) }
+func (m *Hash64) Delete(s string) { m.store.remove(m.get64Block(s)) }
+
+// derive is an empty set with the hasher and the kind of store of m.
+func (m *Hash64) derive(n int) *Hash64 {
+	return &Hash64{
+		store:         m.store.fresh(n),
+		fh64:          m.fh64,
+		collidePanics: m.collidePanics,
+		name:          m.name,
+	}
+}
+
+// compatible tells if the digests of both sets come from the same
+// hasher. Sets without a registered hasher name are never compatible,
+// their hasher funcs can't be told apart.
+func (m *Hash64) compatible(other *Hash64) bool {
+	return m.name != "" && m.name == other.name
+}
+
+// Union is a new set of the digests in m or in other, or ErrIncompatible
+// if their hashers differ. It works on digests alone, the keys are not
+// needed.
+func (m *Hash64) Union(other *Hash64) (*Hash64, error) {
+	if !m.compatible(other) {
+		return nil, ErrIncompatible
+	}
+	out := m.derive(m.Len() + other.Len())
+	m.store.each(func(d uint64) { out.store.add(d) })
+	other.store.each(func(d uint64) { out.store.add(d) })
+	return out, nil
+}
+
+// Intersect is a new set of the digests in both m and other, or
+// ErrIncompatible if their hashers differ.
+func (m *Hash64) Intersect(other *Hash64) (*Hash64, error) {
+	if !m.compatible(other) {
+		return nil, ErrIncompatible
+	}
+	small, large := m, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	out := m.derive(small.Len())
+	small.store.each(func(d uint64) {
+		if large.store.has(d) {
+			out.store.add(d)
+		}
+	})
+	return out, nil
+}
+
+// Difference is a new set of the digests in m but not in other, or
+// ErrIncompatible if their hashers differ.
+func (m *Hash64) Difference(other *Hash64) (*Hash64, error) {
+	if !m.compatible(other) {
+		return nil, ErrIncompatible
+	}
+	out := m.derive(m.Len())
+	m.store.each(func(d uint64) {
+		if !other.store.has(d) {
+			out.store.add(d)
+		}
+	})
+	return out, nil
+}
+
+// XOR is a new set of the digests in either m or other but not both, or
+// ErrIncompatible if their hashers differ.
+func (m *Hash64) XOR(other *Hash64) (*Hash64, error) {
+	if !m.compatible(other) {
+		return nil, ErrIncompatible
+	}
+	out := m.derive(m.Len() + other.Len())
+	m.store.each(func(d uint64) {
+		if !other.store.has(d) {
+			out.store.add(d)
+		}
+	})
+	other.store.each(func(d uint64) {
+		if !m.store.has(d) {
+			out.store.add(d)
+		}
+	})
+	return out, nil
+}
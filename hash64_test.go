@@ -7,8 +7,9 @@ import (
 )
 
 var hash64table = map[string]func(int, bool) *set.Hash64{
-	"Spooky64":   set.NewSpooky64,
-	"Farmhash64": set.NewFarm64,
+	"Spooky64":        set.NewSpooky64,
+	"Farmhash64":      set.NewFarm64,
+	"FarmRobinHood64": set.NewFarmRobinHood64,
 }
 
 func TestHash64_Empty(t *testing.T) { checkHash64(t, 0, []string{}) }
@@ -50,3 +51,80 @@ func checkHash64SetOp(t *testing.T, n int) {
 		checkSetOp(t, func() set.Set { return hashset(n, false) })
 	}
 }
+
+func TestHash64_RobinHoodDelete(t *testing.T) {
+	// a tiny digest space crowds the table with long probe chains
+	h := set.NewRobinHoodFunc64(0, func(b []byte) uint64 { return uint64(len(b)) % 64 }, false)
+	var keys []string
+	for i := 0; i < 64; i++ {
+		keys = append(keys, string(make([]byte, i)))
+	}
+	for _, k := range keys {
+		h.Add(k)
+	}
+	for i, k := range keys {
+		if i%3 == 0 {
+			h.Delete(k)
+		}
+	}
+	for i, k := range keys {
+		if h.Contains(k) != (i%3 != 0) {
+			t.Fatalf("key of length %d: should be contained: %v", i, i%3 != 0)
+		}
+	}
+	if want := 64 - 22; h.Len() != want {
+		t.Fatalf("want %d keys, got %d", want, h.Len())
+	}
+}
+
+func TestHash64_DigestOperations(t *testing.T) {
+	fill := func(h *set.Hash64, keys []string) *set.Hash64 {
+		for _, k := range keys {
+			h.Add(k)
+		}
+		return h
+	}
+	for name, hashset := range hash64table {
+		t.Logf("-- Hash64: %q --", name)
+		a := fill(hashset(0, true), []string{"A", "B", "C"})
+		b := fill(hashset(0, true), []string{"B", "C", "D"})
+
+		union, err := a.Union(b)
+		checkDigestOp(t, union, err, []string{"A", "B", "C", "D"})
+		inter, err := a.Intersect(b)
+		checkDigestOp(t, inter, err, []string{"B", "C"})
+		diff, err := a.Difference(b)
+		checkDigestOp(t, diff, err, []string{"A"})
+		xor, err := a.XOR(b)
+		checkDigestOp(t, xor, err, []string{"A", "D"})
+	}
+}
+
+func TestHash64_DigestOperationsIncompatible(t *testing.T) {
+	a, b := set.NewFarm64(0, true), set.NewSpooky64(0, true)
+	if _, err := a.Union(b); err != set.ErrIncompatible {
+		t.Fatalf("want %v combining farm and spooky digests, got %v", set.ErrIncompatible, err)
+	}
+	// unnamed hasher funcs can't be told apart
+	c := set.NewHashFunc64(0, func(b []byte) uint64 { return 0 }, true)
+	if _, err := c.Intersect(c); err != set.ErrIncompatible {
+		t.Fatalf("want %v combining unnamed hashers, got %v", set.ErrIncompatible, err)
+	}
+}
+
+// checkDigestOp verifies that the result of a digest operation holds
+// exactly want out of the keys A to E.
+func checkDigestOp(t *testing.T, got set.Set, err error, want []string) {
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Len() != len(want) {
+		t.Fatalf("want %d digests, got %d", len(want), got.Len())
+	}
+	in := setFromList(want)
+	for _, k := range []string{"A", "B", "C", "D", "E"} {
+		if got.Contains(k) != in.Contains(k) {
+			t.Fatalf("%q should be contained: %v", k, in.Contains(k))
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package set
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha256" // registers SHA-256
+	_ "crypto/sha512" // registers SHA-512/256
+	"hash"
+	"sync"
+)
+
+var (
+	hashCryptoIsMutable MutableSet = NewHashCrypto(0, crypto.SHA256, 12, true)
+)
+
+// HashCrypto is a hash based set, using a cryptographic hasher whose
+// digests are truncated to a width of bytes. The digests are kept back to
+// back in a flat open addressing table, so each key takes about width
+// bytes.
+type HashCrypto struct {
+	hashers       sync.Pool // of *cryptoHasher, so readers don't share one
+	width         int
+	collidePanics bool
+
+	digests []byte   // a slot of width bytes per digest
+	used    []uint64 // a bit per slot in use
+	mask    uint64   // slots - 1
+	n       int
+}
+
+// hashCryptoMaxWidth is the size of the largest crypto.Hash digest.
+const hashCryptoMaxWidth = 64
+
+// cryptoHasher is a hasher and a scratch buffer for its full digests.
+type cryptoHasher struct {
+	h   hash.Hash
+	sum []byte
+}
+
+// NewHashCrypto creates a hash set using the hash h, keeping the first
+// width bytes of its digests. The package of h must be linked in, like
+// golang.org/x/crypto/blake2b for crypto.BLAKE2b_256. It panics if h is
+// not available or if width is not between 1 and the size of h.
+func NewHashCrypto(n int, h crypto.Hash, width int, collidePanics bool) *HashCrypto {
+	if !h.Available() {
+		panic("set: crypto hash " + h.String() + " is not linked in")
+	}
+	if width < 1 || width > h.Size() || width > hashCryptoMaxWidth {
+		panic("set: digest width must be from 1 byte to the size of the hash")
+	}
+	m := &HashCrypto{
+		width:         width,
+		collidePanics: collidePanics,
+	}
+	m.hashers.New = func() interface{} {
+		return &cryptoHasher{h: h.New(), sum: make([]byte, 0, h.Size())}
+	}
+	slots := uint64(8)
+	for slots*7/8 < uint64(n) {
+		slots <<= 1
+	}
+	m.reset(slots)
+	return m
+}
+
+func (m *HashCrypto) reset(slots uint64) {
+	m.digests = make([]byte, slots*uint64(m.width))
+	m.used = make([]uint64, (slots+63)/64)
+	m.mask = slots - 1
+	m.n = 0
+}
+
+// getCryptoBlock hashes s with a hasher of the pool, keeping the first
+// width bytes of the digest in d.
+func (m *HashCrypto) getCryptoBlock(s string, d *[hashCryptoMaxWidth]byte) []byte {
+	ch := m.hashers.Get().(*cryptoHasher)
+	ch.h.Reset()
+	_, _ = ch.h.Write([]byte(s))
+	ch.sum = ch.h.Sum(ch.sum[:0])
+	n := copy(d[:], ch.sum[:m.width])
+	m.hashers.Put(ch)
+	return d[:n]
+}
+
+// home is the first slot probed for a digest, from its first bytes.
+func (m *HashCrypto) home(d []byte) uint64 {
+	var h uint64
+	for i := 0; i < len(d) && i < 8; i++ {
+		h = h<<8 | uint64(d[i])
+	}
+	return h & m.mask
+}
+
+func (m *HashCrypto) isUsed(i uint64) bool { return m.used[i/64]&(1<<(i%64)) != 0 }
+
+func (m *HashCrypto) slot(i uint64) []byte {
+	return m.digests[i*uint64(m.width) : (i+1)*uint64(m.width)]
+}
+
+// find gives the slot of the digest, or of the free slot where it would
+// go, and whether it was found.
+func (m *HashCrypto) find(d []byte) (uint64, bool) {
+	i := m.home(d)
+	for m.isUsed(i) {
+		if bytes.Equal(m.slot(i), d) {
+			return i, true
+		}
+		i = (i + 1) & m.mask
+	}
+	return i, false
+}
+
+func (m *HashCrypto) put(i uint64, d []byte) {
+	copy(m.slot(i), d)
+	m.used[i/64] |= 1 << (i % 64)
+	m.n++
+}
+
+func (m *HashCrypto) grow() {
+	oldDigests, oldUsed := m.digests, m.used
+	m.reset(2 * (m.mask + 1))
+	for w, bits := range oldUsed {
+		for b := uint64(0); b < 64; b++ {
+			if bits&(1<<b) == 0 {
+				continue
+			}
+			j := uint64(w)*64 + b
+			d := oldDigests[j*uint64(m.width) : (j+1)*uint64(m.width)]
+			i, _ := m.find(d)
+			m.put(i, d)
+		}
+	}
+}
+
+// Add the key to the set.
+func (m *HashCrypto) Add(s string) {
+	var d [hashCryptoMaxWidth]byte
+	block := m.getCryptoBlock(s, &d)
+	i, ok := m.find(block)
+	if ok {
+		if m.collidePanics {
+			panic("Collision with '" + s + "'")
+		}
+		return
+	}
+	if uint64(m.n+1) > (m.mask+1)*7/8 {
+		m.grow()
+		i, _ = m.find(block)
+	}
+	m.put(i, block)
+}
+
+// Contains tells if this key was in the set at least once. It is safe to
+// call from many goroutines, as long as none changes the set.
+func (m *HashCrypto) Contains(s string) bool {
+	var d [hashCryptoMaxWidth]byte
+	_, ok := m.find(m.getCryptoBlock(s, &d))
+	return ok
+}
+
+// IsEmpty tells if this set is empty.
+func (m *HashCrypto) IsEmpty() bool { return m.n == 0 }
+
+// Len is the length of this set.
+func (m *HashCrypto) Len() int { return m.n }
+
+// Delete the element form this set. The digests that follow it are moved
+// back into the hole when it is on their way, so no tombstone is left.
+func (m *HashCrypto) Delete(s string) {
+	var d [hashCryptoMaxWidth]byte
+	hole, ok := m.find(m.getCryptoBlock(s, &d))
+	if !ok {
+		return
+	}
+	for i := (hole + 1) & m.mask; m.isUsed(i); i = (i + 1) & m.mask {
+		// a digest moves back if its home is not between the hole and it
+		if (i-m.home(m.slot(i)))&m.mask >= (i-hole)&m.mask {
+			copy(m.slot(hole), m.slot(i))
+			hole = i
+		}
+	}
+	m.used[hole/64] &^= 1 << (hole % 64)
+	m.n--
+}
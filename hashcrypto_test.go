@@ -0,0 +1,83 @@
+package set_test
+
+import (
+	"crypto"
+	"github.com/aybabtme/set"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func newSHA256(n int, collidePanics bool) *set.HashCrypto {
+	return set.NewHashCrypto(n, crypto.SHA256, 12, collidePanics)
+}
+
+func TestHashCrypto_Collision(t *testing.T) { collisionTest(t, newSHA256(0, true)) }
+
+func TestHashCrypto_Empty(t *testing.T) { setTest(t, newSHA256(0, true), []string{}) }
+func TestHashCrypto_One(t *testing.T)   { setTest(t, newSHA256(0, true), []string{"A"}) }
+func TestHashCrypto_Many(t *testing.T)  { setTest(t, newSHA256(0, true), []string{"A", "B", "C"}) }
+func TestHashCrypto_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return newSHA256(0, false) })
+}
+
+func TestHashCrypto_100Many(t *testing.T) {
+	setTest(t, set.NewHashCrypto(100, crypto.SHA512_256, 32, true), []string{"A", "B", "C"})
+}
+
+func TestHashCrypto_Truncated(t *testing.T) {
+	// a single byte of digest makes collisions certain
+	h := set.NewHashCrypto(0, crypto.SHA256, 1, true)
+	defer func() {
+		if recover() == nil {
+			t.Error("should panic on a collision")
+		}
+	}()
+	for i := 0; i < 257; i++ {
+		h.Add(strconv.Itoa(i))
+	}
+}
+
+func TestHashCrypto_Delete(t *testing.T) {
+	h := set.NewHashCrypto(0, crypto.SHA256, 8, true)
+	for i := 0; i < 1000; i++ {
+		h.Add(strconv.Itoa(i))
+	}
+	for i := 0; i < 1000; i += 2 {
+		h.Delete(strconv.Itoa(i))
+	}
+	for i := 1; i < 1000; i += 2 {
+		if !h.Contains(strconv.Itoa(i)) {
+			t.Fatalf("should still contain %d", i)
+		}
+	}
+	if h.Len() != 500 {
+		t.Fatalf("should have 500 keys, has %d", h.Len())
+	}
+}
+
+func TestHashCrypto_ConcurrentContains(t *testing.T) {
+	h := newSHA256(1000, true)
+	for i := 0; i < 1000; i += 2 {
+		h.Add(strconv.Itoa(i))
+	}
+	var wg sync.WaitGroup
+	errs := make(chan int, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if h.Contains(strconv.Itoa(i)) != (i%2 == 0) {
+					errs <- i
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for i := range errs {
+		t.Errorf("wrong answer for %d", i)
+	}
+}
@@ -0,0 +1,123 @@
+package set
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"github.com/dgryski/go-farm"
+	"github.com/dgryski/go-spooky"
+	"sort"
+	"sync"
+)
+
+var (
+	hashersMu  sync.RWMutex
+	hashers64  = make(map[string]func([]byte) uint64)
+	hashers128 = make(map[string]func([]byte) (uint64, uint64))
+)
+
+func init() {
+	RegisterHasher64("farm", farm.Hash64)
+	RegisterHasher64("spooky", spooky.Hash64)
+	RegisterHasher64("xxhash64", XXHash64)
+	RegisterHasher64("murmur3", Murmur3Hash64)
+	RegisterHasher64("fnv1a", FNV1a64)
+	RegisterHasher64("siphash", SipHash64(randomSipKey()))
+
+	RegisterHasher128("farm", farm.Hash128)
+	RegisterHasher128("spooky", func(b []byte) (lo, hi uint64) { spooky.Hash128(b, &lo, &hi); return })
+	RegisterHasher128("murmur3", Murmur3Hash128)
+}
+
+// randomSipKey draws the key of the "siphash" hasher, different in each
+// process.
+func randomSipKey() (k0, k1 uint64) {
+	var key [16]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		panic("set: no randomness for the siphash key: " + err.Error())
+	}
+	return binary.LittleEndian.Uint64(key[:8]), binary.LittleEndian.Uint64(key[8:])
+}
+
+// RegisterHasher64 makes a 64 bits hasher func available by name. It
+// panics if the name is taken.
+func RegisterHasher64(name string, fh64 func([]byte) uint64) {
+	hashersMu.Lock()
+	defer hashersMu.Unlock()
+	if _, dup := hashers64[name]; dup {
+		panic("set: RegisterHasher64 called twice for " + name)
+	}
+	hashers64[name] = fh64
+}
+
+// RegisterHasher128 makes a 128 bits hasher func available by name. It
+// panics if the name is taken.
+func RegisterHasher128(name string, fh128 func([]byte) (uint64, uint64)) {
+	hashersMu.Lock()
+	defer hashersMu.Unlock()
+	if _, dup := hashers128[name]; dup {
+		panic("set: RegisterHasher128 called twice for " + name)
+	}
+	hashers128[name] = fh128
+}
+
+// Hashers64 gives the names of the registered 64 bits hashers, sorted.
+func Hashers64() []string {
+	hashersMu.RLock()
+	defer hashersMu.RUnlock()
+	var names []string
+	for name := range hashers64 {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Hashers128 gives the names of the registered 128 bits hashers, sorted.
+func Hashers128() []string {
+	hashersMu.RLock()
+	defer hashersMu.RUnlock()
+	var names []string
+	for name := range hashers128 {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewNamedHash64 creates a hash set using the 64 bits hasher registered
+// under name, or returns ErrUnknownHasher.
+func NewNamedHash64(n int, name string, collidePanics bool) (*Hash64, error) {
+	hashersMu.RLock()
+	fh64, ok := hashers64[name]
+	hashersMu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownHasher
+	}
+	h := NewHashFunc64(n, fh64, collidePanics)
+	h.name = name
+	return h, nil
+}
+
+// NewNamedHash128 creates a hash set using the 128 bits hasher
+// registered under name, or returns ErrUnknownHasher.
+func NewNamedHash128(n int, name string, collidePanics bool) (*Hash128, error) {
+	hashersMu.RLock()
+	fh128, ok := hashers128[name]
+	hashersMu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownHasher
+	}
+	h := NewHashFunc128(n, fh128, collidePanics)
+	h.name = name
+	return h, nil
+}
+
+// FNV1a64 is the 64 bits FNV-1a hash of b.
+func FNV1a64(b []byte) uint64 {
+	h := uint64(14695981039346656037)
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= 1099511628211
+	}
+	return h
+}
@@ -0,0 +1,81 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"testing"
+)
+
+func TestHashers_Vectors(t *testing.T) {
+	seq := make([]byte, 64)
+	for i := range seq {
+		seq[i] = byte(i)
+	}
+	tests := []struct {
+		name string
+		fh64 func([]byte) uint64
+		in   []byte
+		want uint64
+	}{
+		{"xxhash64", set.XXHash64, []byte(""), 0xef46db3751d8e999},
+		{"xxhash64", set.XXHash64, []byte("abc"), 0x44bc2cf5ad770999},
+		{"fnv1a", set.FNV1a64, []byte(""), 0xcbf29ce484222325},
+		{"fnv1a", set.FNV1a64, []byte("a"), 0xaf63dc4c8601ec8c},
+		{"murmur3", set.Murmur3Hash64, []byte("hello"), 0xcbd8a7b341bd9b02},
+		{"siphash", set.SipHash64(0x0706050403020100, 0x0f0e0d0c0b0a0908), seq[:0], 0x726fdb47dd0e0e31},
+		{"siphash", set.SipHash64(0x0706050403020100, 0x0f0e0d0c0b0a0908), seq[:15], 0xa129ca6149be45e5},
+	}
+	for _, tt := range tests {
+		if got := tt.fh64(tt.in); got != tt.want {
+			t.Errorf("%s(%q): want %#x, got %#x", tt.name, tt.in, tt.want, got)
+		}
+	}
+
+	if h1, h2 := set.Murmur3Hash128([]byte("hello")); h2 != 0x5b1e906a48ae1d19 {
+		t.Errorf("murmur3 128 of %q: got %#x %#x", "hello", h1, h2)
+	}
+}
+
+func TestHashers_Named(t *testing.T) {
+	for _, name := range set.Hashers64() {
+		h, err := set.NewNamedHash64(0, name, true)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if h.Hasher() != name {
+			t.Errorf("want hasher %q, got %q", name, h.Hasher())
+		}
+		t.Logf("-- Hash64: %q --", name)
+		setTest(t, h, web2)
+	}
+	for _, name := range set.Hashers128() {
+		h, err := set.NewNamedHash128(0, name, true)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		t.Logf("-- Hash128: %q --", name)
+		setTest(t, h, web2)
+	}
+
+	if _, err := set.NewNamedHash64(0, "nope", true); err != set.ErrUnknownHasher {
+		t.Errorf("want %v, got %v", set.ErrUnknownHasher, err)
+	}
+}
+
+func TestHashers_Register(t *testing.T) {
+	set.RegisterHasher64("test-identity", func(b []byte) uint64 { return uint64(len(b)) })
+	h, err := set.NewNamedHash64(0, "test-identity", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Add("ab")
+	if !h.Contains("xy") {
+		t.Error("keys of the same length should collide")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice should panic")
+		}
+	}()
+	set.RegisterHasher64("test-identity", set.FNV1a64)
+}
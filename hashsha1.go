@@ -49,3 +49,59 @@ func (m *HashSHA1) Len() int { return len(m.m) }
 
 // Delete the element form this set.
 func (m *HashSHA1) Delete(s string) { delete(m.m, getSHA1Block(s)) }
+
+// Union is a new set of the digests in m or in other. It works on digests
+// alone, the keys are not needed. Both sets hash with SHA1, so they can
+// always be combined.
+func (m *HashSHA1) Union(other *HashSHA1) *HashSHA1 {
+	out := NewHashSHA1(len(m.m)+len(other.m), m.collidePanics)
+	for d := range m.m {
+		out.m[d] = q
+	}
+	for d := range other.m {
+		out.m[d] = q
+	}
+	return out
+}
+
+// Intersect is a new set of the digests in both m and other.
+func (m *HashSHA1) Intersect(other *HashSHA1) *HashSHA1 {
+	small, large := m, other
+	if len(small.m) > len(large.m) {
+		small, large = large, small
+	}
+	out := NewHashSHA1(len(small.m), m.collidePanics)
+	for d := range small.m {
+		if _, ok := large.m[d]; ok {
+			out.m[d] = q
+		}
+	}
+	return out
+}
+
+// Difference is a new set of the digests in m but not in other.
+func (m *HashSHA1) Difference(other *HashSHA1) *HashSHA1 {
+	out := NewHashSHA1(len(m.m), m.collidePanics)
+	for d := range m.m {
+		if _, ok := other.m[d]; !ok {
+			out.m[d] = q
+		}
+	}
+	return out
+}
+
+// XOR is a new set of the digests in either m or other but not both.
+func (m *HashSHA1) XOR(other *HashSHA1) *HashSHA1 {
+	out := NewHashSHA1(len(m.m)+len(other.m), m.collidePanics)
+	for d := range m.m {
+		if _, ok := other.m[d]; !ok {
+			out.m[d] = q
+		}
+	}
+	for d := range other.m {
+		if _, ok := m.m[d]; !ok {
+			out.m[d] = q
+		}
+	}
+	return out
+}
@@ -22,3 +22,17 @@ func TestHashSHA1_100Many(t *testing.T) {
 func TestHashSHA1_100Operations(t *testing.T) {
 	checkSetOp(t, func() set.Set { return set.NewHashSHA1(100, false) })
 }
+
+func TestHashSHA1_DigestOperations(t *testing.T) {
+	a, b := set.NewHashSHA1(0, true), set.NewHashSHA1(0, true)
+	for _, k := range []string{"A", "B", "C"} {
+		a.Add(k)
+	}
+	for _, k := range []string{"B", "C", "D"} {
+		b.Add(k)
+	}
+	checkDigestOp(t, a.Union(b), nil, []string{"A", "B", "C", "D"})
+	checkDigestOp(t, a.Intersect(b), nil, []string{"B", "C"})
+	checkDigestOp(t, a.Difference(b), nil, []string{"A"})
+	checkDigestOp(t, a.XOR(b), nil, []string{"A", "D"})
+}
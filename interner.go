@@ -0,0 +1,161 @@
+package set
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Interner gives each distinct string a dense uint32 ID, from 0 in the
+// order they were added. Strings are kept once, in a chunked arena, and
+// their IDs are found through a table holding no pointers, so sets of
+// strings can be handled as sets of integers.
+type Interner struct {
+	arena   chunkArena
+	strings []internEntry // by ID
+	slots   []internSlot
+	mask    uint64 // slots - 1
+}
+
+// internEntry is where the string of an ID is in the arena.
+type internEntry struct{ chunk, pos, len uint32 }
+
+// internSlot holds an ID off by one, so the zero slot is empty.
+type internSlot struct{ hash, id uint32 }
+
+// NewInterner creates an Interner of capacity n.
+func NewInterner(n int) *Interner {
+	in := &Interner{strings: make([]internEntry, 0, n)}
+	size := uint64(8)
+	for size*7/8 < uint64(n) {
+		size <<= 1
+	}
+	in.resize(size)
+	return in
+}
+
+func (in *Interner) resize(size uint64) {
+	in.slots = make([]internSlot, size)
+	in.mask = size - 1
+	for id := range in.strings {
+		in.place(internSlot{hash: arenaHash(in.String(uint32(id))), id: uint32(id) + 1})
+	}
+}
+
+func (in *Interner) place(sl internSlot) {
+	i := uint64(sl.hash) & in.mask
+	for in.slots[i].id != 0 {
+		i = (i + 1) & in.mask
+	}
+	in.slots[i] = sl
+}
+
+func (in *Interner) bytes(id uint32) []byte {
+	e := in.strings[id]
+	return in.arena.bytes(e.chunk, e.pos, e.len)
+}
+
+func (in *Interner) find(s string, h uint32) (uint32, bool) {
+	for i := uint64(h) & in.mask; in.slots[i].id != 0; i = (i + 1) & in.mask {
+		sl := in.slots[i]
+		if sl.hash == h && string(in.bytes(sl.id-1)) == s {
+			return sl.id - 1, true
+		}
+	}
+	return 0, false
+}
+
+// Add gives the ID of s, giving it the next one if it is new. It panics
+// with ErrOverflow once all the IDs are taken.
+func (in *Interner) Add(s string) uint32 {
+	h := arenaHash(s)
+	if id, ok := in.find(s, h); ok {
+		return id
+	}
+	if uint64(len(in.strings)) >= math.MaxUint32 {
+		panic(ErrOverflow)
+	}
+	if uint64(len(in.strings)+1) > uint64(len(in.slots))*7/8 {
+		in.resize(2 * uint64(len(in.slots)))
+	}
+	id := uint32(len(in.strings))
+	chunk, pos := in.arena.add(s)
+	in.strings = append(in.strings, internEntry{chunk: chunk, pos: pos, len: uint32(len(s))})
+	in.place(internSlot{hash: h, id: id + 1})
+	return id
+}
+
+// ID gives the ID of s, and whether it was added.
+func (in *Interner) ID(s string) (uint32, bool) { return in.find(s, arenaHash(s)) }
+
+// String gives the string of an ID. It panics if the ID was not given by
+// this Interner.
+func (in *Interner) String(id uint32) string { return string(in.bytes(id)) }
+
+// Len is the number of strings, the next ID to be given.
+func (in *Interner) Len() int { return len(in.strings) }
+
+// MarshalBinary encodes the strings in the order of their IDs: their
+// count, the length of each, all as uvarints, then their bytes.
+func (in *Interner) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, binary.MaxVarintLen64*(len(in.strings)+1)+in.arena.size())
+	var tmp [binary.MaxVarintLen64]byte
+	buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(len(in.strings)))]...)
+	for _, e := range in.strings {
+		buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(e.len))]...)
+	}
+	for id := range in.strings {
+		buf = append(buf, in.bytes(uint32(id))...)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes strings encoded by MarshalBinary, which keep
+// their IDs.
+func (in *Interner) UnmarshalBinary(data []byte) error {
+	count, n := binary.Uvarint(data)
+	// every string takes at least a byte for its length
+	if n <= 0 || count > uint64(len(data)-n) {
+		return ErrMalformed
+	}
+	data = data[n:]
+	lens := make([]uint64, count)
+	total := uint64(0)
+	for i := range lens {
+		l, n := binary.Uvarint(data)
+		if n <= 0 || l > math.MaxUint32 {
+			return ErrMalformed
+		}
+		lens[i], total, data = l, total+l, data[n:]
+	}
+	if total != uint64(len(data)) {
+		return ErrMalformed
+	}
+	out := NewInterner(int(count))
+	for id, l := range lens {
+		if out.Add(string(data[:l])) != uint32(id) {
+			return ErrMalformed // a string twice
+		}
+		data = data[l:]
+	}
+	*in = *out
+	return nil
+}
+
+// Intern gives the IDs of the keys of s as a Bitmap, adding the keys that
+// have none yet. Sets interned by the same Interner can then be combined
+// with the operations of Bitmap.
+func (in *Interner) Intern(s ListSet) *Bitmap {
+	b := NewBitmap()
+	for _, k := range s.Keys() {
+		b.Add(in.Add(k))
+	}
+	return b
+}
+
+// Strings gives the strings of the IDs of b, in the order of their IDs.
+// It panics if one of the IDs was not given by this Interner.
+func (in *Interner) Strings(b *Bitmap) []string {
+	out := make([]string, 0, b.Cardinality())
+	b.Each(func(id uint32) { out = append(out, in.String(id)) })
+	return out
+}
@@ -0,0 +1,71 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"testing"
+)
+
+func TestInterner_IDs(t *testing.T) {
+	in := set.NewInterner(0)
+	for i, w := range web2 {
+		if id := in.Add(w); id != uint32(i) {
+			t.Fatalf("%q: want ID %d, got %d", w, i, id)
+		}
+	}
+	for i, w := range web2 {
+		if id := in.Add(w); id != uint32(i) {
+			t.Fatalf("%q added again: want ID %d, got %d", w, i, id)
+		}
+	}
+	if in.Len() != len(web2) {
+		t.Fatalf("want %d strings, got %d", len(web2), in.Len())
+	}
+	for i, w := range web2 {
+		id, ok := in.ID(w)
+		if !ok || id != uint32(i) {
+			t.Fatalf("%q: want ID %d, got %d (found: %v)", w, i, id, ok)
+		}
+		if s := in.String(id); s != w {
+			t.Fatalf("ID %d: want %q, got %q", id, w, s)
+		}
+	}
+	if _, ok := in.ID("not a word"); ok {
+		t.Fatalf("should not have an ID for a string never added")
+	}
+}
+
+func TestInterner_Binary(t *testing.T) {
+	in := set.NewInterner(0)
+	in.Add("")
+	for _, w := range web2 {
+		in.Add(w)
+	}
+	data, err := in.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out set.Interner
+	if err := out.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != in.Len() {
+		t.Fatalf("want %d strings, got %d", in.Len(), out.Len())
+	}
+	for id := 0; id < in.Len(); id++ {
+		if in.String(uint32(id)) != out.String(uint32(id)) {
+			t.Fatalf("ID %d: want %q, got %q", id, in.String(uint32(id)), out.String(uint32(id)))
+		}
+	}
+
+	for _, bad := range [][]byte{
+		{},
+		{2, 1},           // lengths missing
+		{1, 2, 'a'},      // bytes missing
+		{1, 1, 'a', 'b'}, // bytes left over
+		{2, 1, 1, 'a', 'a'},
+	} {
+		if err := out.UnmarshalBinary(bad); err != set.ErrMalformed {
+			t.Errorf("%v: want %v, got %v", bad, set.ErrMalformed, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package set
+
+import (
+	"math"
+)
+
+// keyArena keeps keys in a chunkArena. A key is known by the index of its
+// entry, which stays valid until the arena is compacted.
+type keyArena struct {
+	chunks  chunkArena
+	entries []arenaEntry
+	dead    int // entries of deleted keys
+}
+
+type arenaEntry struct {
+	chunk, pos, len uint32
+	dead            bool
+}
+
+// next is the index the entry of the next key added will have.
+func (a *keyArena) next() uint32 { return uint32(len(a.entries)) }
+
+// add appends a key, giving the index of its entry. It panics with
+// ErrOverflow once all the indexes are taken.
+func (a *keyArena) add(s string) uint32 {
+	if uint64(len(a.entries)) >= math.MaxUint32 {
+		panic(ErrOverflow)
+	}
+	chunk, pos := a.chunks.add(s)
+	a.entries = append(a.entries, arenaEntry{chunk: chunk, pos: pos, len: uint32(len(s))})
+	return uint32(len(a.entries) - 1)
+}
+
+func (a *keyArena) key(i uint32) string {
+	e := a.entries[i]
+	return string(a.chunks.bytes(e.chunk, e.pos, e.len))
+}
+
+// kill marks the key of an entry as deleted, its bytes stay until the
+// arena is compacted.
+func (a *keyArena) kill(i uint32) {
+	if !a.entries[i].dead {
+		a.entries[i].dead = true
+		a.dead++
+	}
+}
+
+// keys gives the keys that are not deleted, in the order they were added.
+func (a *keyArena) keys() []string {
+	keys := make([]string, 0, len(a.entries)-a.dead)
+	for i, e := range a.entries {
+		if !e.dead {
+			keys = append(keys, a.key(uint32(i)))
+		}
+	}
+	return keys
+}
+
+// compact moves the keys that are not deleted to a new arena, giving the
+// new index of each entry. The new index of a deleted key is meaningless.
+func (a *keyArena) compact() []uint32 {
+	remap := make([]uint32, len(a.entries))
+	live := a.entries[:0]
+	var chunks chunkArena
+	for i, e := range a.entries {
+		if e.dead {
+			continue
+		}
+		remap[i] = uint32(len(live))
+		chunk, pos := chunks.move(&a.chunks, e.chunk, e.pos, e.len)
+		live = append(live, arenaEntry{chunk: chunk, pos: pos, len: e.len})
+	}
+	a.chunks, a.entries, a.dead = chunks, live, 0
+	return remap
+}
@@ -0,0 +1,181 @@
+package set
+
+import (
+	"github.com/dgryski/go-farm"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	keyedHashIsMutable MutableSet = NewKeyedFarm64(0, true)
+	keyedHashIsList    ListSet    = NewKeyedFarm64(0, true)
+)
+
+// entryIndex finds the arena entry of a key from its digest.
+type entryIndex interface {
+	// add gives the entry kept with the digest of s, adding it with the
+	// entry e if it was not there yet, which it tells.
+	add(s string, e uint32) (uint32, bool)
+	has(s string) bool
+	// remove the digest of s, giving the entry kept with it.
+	remove(s string) (uint32, bool)
+	len() int
+	// remap replaces every entry e by remap[e].
+	remap(remap []uint32)
+}
+
+// KeyedHash is a hash based set like Hash64, Hash128 or HashSHA1 that
+// also keeps its keys in an arena, so it can list them. Its table keeps
+// the arena entry of each key with its digest, and each key is hashed
+// once. Deleted keys keep their room in the arena until Compact.
+type KeyedHash struct {
+	index         entryIndex
+	arena         keyArena
+	collidePanics bool
+}
+
+// NewKeyedHashFunc64 creates a KeyedHash using a hash64 hasher func, like
+// NewHashFunc64.
+func NewKeyedHashFunc64(n int, fh64 func(s []byte) uint64, collidePanics bool) *KeyedHash {
+	return &KeyedHash{index: index64{make(entryMap, n), fh64}, collidePanics: collidePanics}
+}
+
+// NewKeyedRobinHoodFunc64 creates a KeyedHash using a hash64 hasher func,
+// with its digests in a flat open addressing table like
+// NewRobinHoodFunc64.
+func NewKeyedRobinHoodFunc64(n int, fh64 func(s []byte) uint64, collidePanics bool) *KeyedHash {
+	return &KeyedHash{index: index64{newRobinHoodEntries(n), fh64}, collidePanics: collidePanics}
+}
+
+// NewKeyedFarm64 is a KeyedHash with 64 bits farmhash for hasher.
+func NewKeyedFarm64(n int, collidePanics bool) *KeyedHash {
+	return NewKeyedHashFunc64(n, farm.Hash64, collidePanics)
+}
+
+// NewKeyedHashFunc128 creates a KeyedHash using a hash128 hasher func,
+// like NewHashFunc128.
+func NewKeyedHashFunc128(n int, fh128 func(s []byte) (uint64, uint64), collidePanics bool) *KeyedHash {
+	return &KeyedHash{index: index128{make(map[uint128]uint32, n), fh128}, collidePanics: collidePanics}
+}
+
+// NewKeyedFarm128 is a KeyedHash with 128 bits farmhash for hasher.
+func NewKeyedFarm128(n int, collidePanics bool) *KeyedHash {
+	return NewKeyedHashFunc128(n, farm.Hash128, collidePanics)
+}
+
+// NewKeyedHashSHA1 creates a KeyedHash using SHA1, like NewHashSHA1.
+func NewKeyedHashSHA1(n int, collidePanics bool) *KeyedHash {
+	return &KeyedHash{index: make(indexSHA1, n), collidePanics: collidePanics}
+}
+
+// Add the key to the set. Of keys with the same digest, the first one
+// added is kept.
+func (k *KeyedHash) Add(s string) {
+	e, added := k.index.add(s, k.arena.next())
+	if added {
+		k.arena.add(s)
+	} else if k.collidePanics && k.arena.key(e) != s {
+		panic("Collision with '" + s + "'")
+	}
+}
+
+// Contains tells if this key was in the set at least once.
+func (k *KeyedHash) Contains(s string) bool { return k.index.has(s) }
+
+// Delete the element form this set.
+func (k *KeyedHash) Delete(s string) {
+	if e, ok := k.index.remove(s); ok {
+		k.arena.kill(e)
+	}
+}
+
+// IsEmpty tells if this set is empty.
+func (k *KeyedHash) IsEmpty() bool { return k.index.len() == 0 }
+
+// Len is the length of this set.
+func (k *KeyedHash) Len() int { return k.index.len() }
+
+// Keys gives all the keys in this set, in the order they were added.
+func (k *KeyedHash) Keys() []string { return k.arena.keys() }
+
+// Compact reclaims the room of the deleted keys in the arena.
+func (k *KeyedHash) Compact() { k.index.remap(k.arena.compact()) }
+
+// index64 is an entryIndex over 64 bits digests.
+type index64 struct {
+	store entryStore
+	fh64  func(s []byte) uint64
+}
+
+func (x index64) digest(s string) uint64 { return x.fh64([]byte(s)) }
+
+func (x index64) add(s string, e uint32) (uint32, bool) { return x.store.put(x.digest(s), e) }
+func (x index64) has(s string) bool                     { return x.store.has(x.digest(s)) }
+func (x index64) remove(s string) (uint32, bool)        { return x.store.take(x.digest(s)) }
+func (x index64) len() int                              { return x.store.len() }
+func (x index64) remap(remap []uint32)                  { x.store.remap(remap) }
+
+// index128 is an entryIndex over 128 bits digests.
+type index128 struct {
+	m     map[uint128]uint32
+	fh128 func(s []byte) (uint64, uint64)
+}
+
+func (x index128) digest(s string) uint128 {
+	lo, hi := x.fh128([]byte(s))
+	return uint128{lo: lo, hi: hi}
+}
+
+func (x index128) add(s string, e uint32) (uint32, bool) {
+	d := x.digest(s)
+	if kept, ok := x.m[d]; ok {
+		return kept, false
+	}
+	x.m[d] = e
+	return e, true
+}
+
+func (x index128) has(s string) bool { _, ok := x.m[x.digest(s)]; return ok }
+
+func (x index128) remove(s string) (uint32, bool) {
+	d := x.digest(s)
+	e, ok := x.m[d]
+	delete(x.m, d)
+	return e, ok
+}
+
+func (x index128) len() int { return len(x.m) }
+
+func (x index128) remap(remap []uint32) {
+	for d, e := range x.m {
+		x.m[d] = remap[e]
+	}
+}
+
+// indexSHA1 is an entryIndex over SHA1 digests.
+type indexSHA1 map[sha1block]uint32
+
+func (x indexSHA1) add(s string, e uint32) (uint32, bool) {
+	d := getSHA1Block(s)
+	if kept, ok := x[d]; ok {
+		return kept, false
+	}
+	x[d] = e
+	return e, true
+}
+
+func (x indexSHA1) has(s string) bool { _, ok := x[getSHA1Block(s)]; return ok }
+
+func (x indexSHA1) remove(s string) (uint32, bool) {
+	d := getSHA1Block(s)
+	e, ok := x[d]
+	delete(x, d)
+	return e, ok
+}
+
+func (x indexSHA1) len() int { return len(x) }
+
+func (x indexSHA1) remap(remap []uint32) {
+	for d, e := range x {
+		x[d] = remap[e]
+	}
+}
@@ -0,0 +1,109 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"strconv"
+	"testing"
+)
+
+func TestKeyedHash64_Empty(t *testing.T) {
+	setTest(t, set.NewKeyedFarm64(0, true), []string{})
+}
+func TestKeyedHash64_Many(t *testing.T) {
+	setTest(t, set.NewKeyedFarm64(0, true), []string{"A", "B", "C"})
+}
+func TestKeyedHash64_Web2(t *testing.T) {
+	setTest(t, set.NewKeyedRobinHoodFunc64(0, set.FNV1a64, true), web2)
+}
+func TestKeyedHash64_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return set.NewKeyedFarm64(0, false) })
+}
+
+func TestKeyedHash128_Empty(t *testing.T) {
+	setTest(t, set.NewKeyedFarm128(0, true), []string{})
+}
+func TestKeyedHash128_Many(t *testing.T) {
+	setTest(t, set.NewKeyedFarm128(0, true), []string{"A", "B", "C"})
+}
+func TestKeyedHash128_Web2(t *testing.T) {
+	setTest(t, set.NewKeyedFarm128(0, true), web2)
+}
+func TestKeyedHash128_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return set.NewKeyedFarm128(0, false) })
+}
+
+func TestKeyedHashSHA1_Empty(t *testing.T) {
+	setTest(t, set.NewKeyedHashSHA1(0, true), []string{})
+}
+func TestKeyedHashSHA1_Many(t *testing.T) {
+	setTest(t, set.NewKeyedHashSHA1(0, true), []string{"A", "B", "C"})
+}
+func TestKeyedHashSHA1_Web2(t *testing.T) {
+	setTest(t, set.NewKeyedHashSHA1(0, true), web2)
+}
+func TestKeyedHashSHA1_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return set.NewKeyedHashSHA1(0, false) })
+}
+
+func TestKeyedHash64_Collision(t *testing.T) {
+	// a hasher giving the length of the key makes collisions certain
+	k := set.NewKeyedHashFunc64(0, func(b []byte) uint64 { return uint64(len(b)) }, true)
+	k.Add("a")
+	// the same key again is not a collision, the keys tell them apart
+	k.Add("a")
+	defer func() {
+		if recover() == nil {
+			t.Error("should panic on a collision")
+		}
+	}()
+	k.Add("b")
+}
+
+func TestKeyedHash64_Compact(t *testing.T) {
+	compactTest(t, set.NewKeyedFarm64(0, true))
+}
+func TestKeyedHash64_RobinHoodCompact(t *testing.T) {
+	compactTest(t, set.NewKeyedRobinHoodFunc64(0, set.FNV1a64, true))
+}
+func TestKeyedHash128_Compact(t *testing.T) {
+	compactTest(t, set.NewKeyedFarm128(0, true))
+}
+func TestKeyedHashSHA1_Compact(t *testing.T) {
+	compactTest(t, set.NewKeyedHashSHA1(0, true))
+}
+
+func compactTest(t *testing.T, k *set.KeyedHash) {
+	var want []string
+	for i := 0; i < 1000; i++ {
+		k.Add(strconv.Itoa(i))
+	}
+	for i := 0; i < 1000; i++ {
+		if i%3 == 0 {
+			k.Delete(strconv.Itoa(i))
+		} else {
+			want = append(want, strconv.Itoa(i))
+		}
+	}
+	listableTest(t, k, want)
+
+	k.Compact()
+	listableTest(t, k, want)
+
+	// deleting after compaction must find the moved keys
+	for _, w := range want[:100] {
+		k.Delete(w)
+	}
+	k.Add("0")
+	listableTest(t, k, append(want[100:], "0"))
+}
+
+func TestKeyedHashSHA1_Union(t *testing.T) {
+	a := set.NewKeyedHashSHA1(0, true)
+	for _, w := range web2[:50] {
+		a.Add(w)
+	}
+	b := setFromList(web2[25:])
+	out := set.NewGoMap(0)
+	set.Union(a, b, out)
+	listableTest(t, out, web2)
+}
@@ -0,0 +1,136 @@
+package set
+
+import (
+	"sort"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	loudsIsPrefix  PrefixSet  = NewLOUDS(NewGoMap(0))
+	loudsIsChecked CheckedSet = NewLOUDS(NewGoMap(0))
+)
+
+// LOUDS is a static set of string implemented using a succinct trie. The
+// shape of the trie is a level-order unary degree sequence: "10" for a
+// super root, then for each node in breadth-first order, a 1 per child
+// followed by a 0. Nodes are numbered in the same order, root being 0,
+// which makes each of them cost about 11 bits, plus the rank samples.
+type LOUDS struct {
+	louds    bitVector
+	terminal bitVector // one bit per node, set when a key ends there
+	labels   []byte    // label of each node but the root
+	count    int
+}
+
+type loudsRange struct{ lo, hi, depth int }
+
+// NewLOUDS builds a LOUDS holding the keys of s.
+func NewLOUDS(s ListSet) *LOUDS {
+	keys := s.Keys()
+	sort.Strings(keys)
+
+	l := &LOUDS{count: len(keys)}
+	l.louds.push(true)
+	l.louds.push(false)
+
+	queue := []loudsRange{{lo: 0, hi: len(keys), depth: 0}}
+	for len(queue) != 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		i := node.lo
+		// sorted, so a key ending at this node comes first
+		terminal := i < node.hi && len(keys[i]) == node.depth
+		l.terminal.push(terminal)
+		if terminal {
+			i++
+		}
+		for i < node.hi {
+			c := keys[i][node.depth]
+			j := i + 1
+			for j < node.hi && keys[j][node.depth] == c {
+				j++
+			}
+			l.louds.push(true)
+			l.labels = append(l.labels, c)
+			queue = append(queue, loudsRange{lo: i, hi: j, depth: node.depth + 1})
+			i = j
+		}
+		l.louds.push(false)
+	}
+	l.louds.build()
+	return l
+}
+
+// children gives the positions in the LOUDS bits of the children of node.
+func (l *LOUDS) children(node int) (from, to int) {
+	return l.louds.select0(node+1) + 1, l.louds.select0(node + 2)
+}
+
+// nodeAt gives the node described by the 1 at position pos.
+func (l *LOUDS) nodeAt(pos int) int { return l.louds.rank1(pos+1) - 1 }
+
+func (l *LOUDS) child(node int, c byte) (int, bool) {
+	from, to := l.children(node)
+	// labels of siblings are sorted
+	i := sort.Search(to-from, func(i int) bool { return l.labels[l.nodeAt(from+i)-1] >= c })
+	if i == to-from || l.labels[l.nodeAt(from+i)-1] != c {
+		return 0, false
+	}
+	return l.nodeAt(from + i), true
+}
+
+func (l *LOUDS) walk(key string) (int, bool) {
+	node := 0
+	for i := 0; i < len(key); i++ {
+		next, ok := l.child(node, key[i])
+		if !ok {
+			return 0, false
+		}
+		node = next
+	}
+	return node, true
+}
+
+// Add is not supported, a LOUDS is immutable.
+func (l *LOUDS) Add(s string) { panic(ErrImmutable) }
+
+// TryAdd always returns ErrImmutable.
+func (l *LOUDS) TryAdd(s string) error { return ErrImmutable }
+
+// Contains tells if this key is in the set.
+func (l *LOUDS) Contains(s string) bool {
+	node, ok := l.walk(s)
+	return ok && l.terminal.get(node)
+}
+
+// IsEmpty tells if this set is empty.
+func (l *LOUDS) IsEmpty() bool { return l.count == 0 }
+
+// Len is the length of this set.
+func (l *LOUDS) Len() int { return l.count }
+
+// Keys gives all the keys in this LOUDS, in sorted order.
+func (l *LOUDS) Keys() []string { return l.PrefixKeys("") }
+
+// PrefixKeys gives the keys starting with prefix, in sorted order.
+func (l *LOUDS) PrefixKeys(prefix string) []string {
+	node, ok := l.walk(prefix)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	l.collect(node, []byte(prefix), &keys)
+	return keys
+}
+
+func (l *LOUDS) collect(node int, key []byte, keys *[]string) {
+	if l.terminal.get(node) {
+		*keys = append(*keys, string(key))
+	}
+	from, to := l.children(node)
+	for pos := from; pos < to; pos++ {
+		child := l.nodeAt(pos)
+		l.collect(child, append(key, l.labels[child-1]), keys)
+	}
+}
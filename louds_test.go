@@ -0,0 +1,22 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"testing"
+)
+
+func buildLOUDS(s set.ListSet) set.Set { return set.NewLOUDS(s) }
+
+func TestLOUDS_Empty(t *testing.T)    { staticTest(t, buildLOUDS, []string{}) }
+func TestLOUDS_One(t *testing.T)      { staticTest(t, buildLOUDS, []string{"A"}) }
+func TestLOUDS_Many(t *testing.T)     { staticTest(t, buildLOUDS, []string{"A", "B", "C"}) }
+func TestLOUDS_Words(t *testing.T)    { staticTest(t, buildLOUDS, setA.Keys()) }
+func TestLOUDS_EmptyKey(t *testing.T) { staticTest(t, buildLOUDS, []string{"", "a", "ab"}) }
+
+func TestLOUDS_Prefix(t *testing.T) {
+	l := set.NewLOUDS(setFromList([]string{"aba", "abac", "abacus", "abb", "b"}))
+	prefixTest(t, l, "", []string{"aba", "abac", "abacus", "abb", "b"})
+	prefixTest(t, l, "aba", []string{"aba", "abac", "abacus"})
+	prefixTest(t, l, "abacu", []string{"abacus"})
+	prefixTest(t, l, "abc", []string{})
+}
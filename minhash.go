@@ -0,0 +1,172 @@
+package set
+
+import (
+	"encoding/binary"
+	"github.com/dgryski/go-farm"
+	"math"
+	"sort"
+)
+
+// MinHasher computes MinHash signatures of sets: for each of k hash
+// functions, the smallest hash of the keys of a set. The probability
+// that two sets get the same minimum for one function is their Jaccard
+// similarity, so comparing signatures estimates it in O(k), with a
+// standard error of about 1/sqrt(k).
+type MinHasher struct {
+	fh64  func([]byte) uint64
+	seeds []uint64
+}
+
+// NewMinHasher creates a MinHasher of signatures of k values, deriving
+// its hash functions from a 64 bits hasher func.
+func NewMinHasher(k int, fh64 func([]byte) uint64) *MinHasher {
+	seeds := make([]uint64, k)
+	state := uint64(k)
+	for i := range seeds {
+		seeds[i] = splitmix64(&state)
+	}
+	return &MinHasher{fh64: fh64, seeds: seeds}
+}
+
+// NewFarmMinHasher is a MinHasher with farmhash for hasher.
+func NewFarmMinHasher(k int) *MinHasher { return NewMinHasher(k, farm.Hash64) }
+
+// MinHashSignature is the signature of a set computed by a MinHasher.
+type MinHashSignature []uint64
+
+// Signature of the keys of s.
+func (mh *MinHasher) Signature(s ListSet) MinHashSignature {
+	sig := make(MinHashSignature, len(mh.seeds))
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for _, k := range s.Keys() {
+		h := mh.fh64([]byte(k))
+		for i, seed := range mh.seeds {
+			if hi := mix64(h ^ seed); hi < sig[i] {
+				sig[i] = hi
+			}
+		}
+	}
+	return sig
+}
+
+// Jaccard estimates the Jaccard similarity of the sets of both
+// signatures, which must come from the same MinHasher.
+func (sig MinHashSignature) Jaccard(other MinHashSignature) float64 {
+	if len(sig) != len(other) {
+		panic("set: signatures of different MinHashers")
+	}
+	if len(sig) == 0 {
+		return 0
+	}
+	same := 0
+	for i := range sig {
+		if sig[i] == other[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(sig))
+}
+
+// LSHIndex finds the sets similar to a set among many, without comparing
+// it with each of them. Signatures are cut in bands of rows: two sets
+// become candidates if all the rows of one of their bands are equal,
+// which is likely above a similarity of about (1/bands)^(1/rows) and
+// unlikely below. Candidates are then checked against their signature.
+type LSHIndex struct {
+	mh         *MinHasher
+	threshold  float64
+	bands      int
+	rows       int
+	buckets    []map[uint64][]string // for each band, the sets per band hash
+	signatures map[string]MinHashSignature
+}
+
+// NewLSHIndex creates an LSHIndex of the signatures made by mh, finding
+// the sets of a similarity of at least threshold.
+func NewLSHIndex(mh *MinHasher, threshold float64) *LSHIndex {
+	bands, rows := lshBands(len(mh.seeds), threshold)
+	idx := &LSHIndex{
+		mh:         mh,
+		threshold:  threshold,
+		bands:      bands,
+		rows:       rows,
+		buckets:    make([]map[uint64][]string, bands),
+		signatures: make(map[string]MinHashSignature),
+	}
+	for b := range idx.buckets {
+		idx.buckets[b] = make(map[uint64][]string)
+	}
+	return idx
+}
+
+// lshBands picks the bands and rows fitting in k values whose similarity
+// threshold is the closest to the wanted one.
+func lshBands(k int, threshold float64) (bands, rows int) {
+	bands, rows = k, 1
+	best := math.Inf(1)
+	for r := 1; r <= k; r++ {
+		b := k / r
+		if d := math.Abs(math.Pow(1/float64(b), 1/float64(r)) - threshold); d < best {
+			bands, rows, best = b, r, d
+		}
+	}
+	return bands, rows
+}
+
+func (idx *LSHIndex) bandHash(sig MinHashSignature, band int) uint64 {
+	buf := make([]byte, 8*idx.rows)
+	for i, v := range sig[band*idx.rows : (band+1)*idx.rows] {
+		binary.LittleEndian.PutUint64(buf[8*i:], v)
+	}
+	return idx.mh.fh64(buf)
+}
+
+// Insert the signature of a set, known by id.
+func (idx *LSHIndex) Insert(id string, sig MinHashSignature) {
+	idx.signatures[id] = sig
+	for b, bucket := range idx.buckets {
+		h := idx.bandHash(sig, b)
+		bucket[h] = append(bucket[h], id)
+	}
+}
+
+// Query gives the ids of the sets whose estimated similarity with the
+// set of sig is at least the threshold, most similar first.
+func (idx *LSHIndex) Query(sig MinHashSignature) []string {
+	seen := make(map[string]bool)
+	var found []string
+	var similarity []float64
+	for b, bucket := range idx.buckets {
+		for _, id := range bucket[idx.bandHash(sig, b)] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if j := sig.Jaccard(idx.signatures[id]); j >= idx.threshold {
+				found = append(found, id)
+				similarity = append(similarity, j)
+			}
+		}
+	}
+	sort.Sort(bySimilarity{found, similarity})
+	return found
+}
+
+type bySimilarity struct {
+	ids        []string
+	similarity []float64
+}
+
+func (s bySimilarity) Len() int { return len(s.ids) }
+func (s bySimilarity) Less(i, j int) bool {
+	if s.similarity[i] != s.similarity[j] {
+		return s.similarity[i] > s.similarity[j]
+	}
+	return s.ids[i] < s.ids[j]
+}
+func (s bySimilarity) Swap(i, j int) {
+	s.ids[i], s.ids[j] = s.ids[j], s.ids[i]
+	s.similarity[i], s.similarity[j] = s.similarity[j], s.similarity[i]
+}
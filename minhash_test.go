@@ -0,0 +1,67 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func jaccard(a, b set.ListSet) float64 {
+	u, i := set.NewGoMap(0), set.NewGoMap(0)
+	set.Union(a, b, u)
+	set.Intersect(a, b, i)
+	if u.IsEmpty() {
+		return 0
+	}
+	return float64(i.Len()) / float64(u.Len())
+}
+
+func numbered(prefix string, from, to int) set.GoMap {
+	s := set.NewGoMap(to - from)
+	for i := from; i < to; i++ {
+		s.Add(prefix + strconv.Itoa(i))
+	}
+	return s
+}
+
+func TestMinHash_Jaccard(t *testing.T) {
+	const k = 512
+	mh := set.NewFarmMinHasher(k)
+	a := numbered("key", 0, 1000)
+	for _, to := range []int{1000, 1500, 2000, 4000} {
+		b := numbered("key", 500, to)
+		want := jaccard(a, b)
+		got := mh.Signature(a).Jaccard(mh.Signature(b))
+		// 4 standard errors
+		if math.Abs(got-want) > 4/math.Sqrt(k) {
+			t.Errorf("estimated similarity %.3f, want %.3f", got, want)
+		}
+	}
+
+	if got := mh.Signature(a).Jaccard(mh.Signature(setFromList(web2))); got > 4/math.Sqrt(k) {
+		t.Errorf("disjoint sets have a similarity of %.3f", got)
+	}
+}
+
+func TestLSHIndex_Query(t *testing.T) {
+	mh := set.NewFarmMinHasher(128)
+	idx := set.NewLSHIndex(mh, 0.7)
+
+	base := numbered("key", 0, 1000)
+	idx.Insert("same", mh.Signature(base))
+	idx.Insert("near", mh.Signature(numbered("key", 50, 1000)))
+	idx.Insert("far", mh.Signature(numbered("key", 700, 2000)))
+	idx.Insert("other", mh.Signature(numbered("other", 0, 1000)))
+
+	got := idx.Query(mh.Signature(base))
+	want := []string{"same", "near"}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("index %d: want %q got %q", i, want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package set
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	murmurC1 uint64 = 0x87c37b91114253d5
+	murmurC2 uint64 = 0x4cf5ad432745937f
+)
+
+// Murmur3Hash128 is the 128 bits x64 variant of MurmurHash3 of b, with a
+// seed of 0.
+func Murmur3Hash128(b []byte) (h1, h2 uint64) {
+	n := len(b)
+	for ; len(b) >= 16; b = b[16:] {
+		k1 := binary.LittleEndian.Uint64(b)
+		k2 := binary.LittleEndian.Uint64(b[8:])
+		h1 ^= murmurMixK1(k1)
+		h1 = bits.RotateLeft64(h1, 27) + h2
+		h1 = h1*5 + 0x52dce729
+		h2 ^= murmurMixK2(k2)
+		h2 = bits.RotateLeft64(h2, 31) + h1
+		h2 = h2*5 + 0x38495ab5
+	}
+
+	var k1, k2 uint64
+	for i := len(b) - 1; i >= 8; i-- {
+		k2 = k2<<8 | uint64(b[i])
+	}
+	low := len(b)
+	if low > 8 {
+		low = 8
+	}
+	for i := low - 1; i >= 0; i-- {
+		k1 = k1<<8 | uint64(b[i])
+	}
+	if len(b) > 8 {
+		h2 ^= murmurMixK2(k2)
+	}
+	if len(b) > 0 {
+		h1 ^= murmurMixK1(k1)
+	}
+
+	h1 ^= uint64(n)
+	h2 ^= uint64(n)
+	h1 += h2
+	h2 += h1
+	h1 = murmurFmix(h1)
+	h2 = murmurFmix(h2)
+	h1 += h2
+	h2 += h1
+	return h1, h2
+}
+
+// Murmur3Hash64 is the first half of Murmur3Hash128.
+func Murmur3Hash64(b []byte) uint64 {
+	h1, _ := Murmur3Hash128(b)
+	return h1
+}
+
+func murmurMixK1(k uint64) uint64 { return bits.RotateLeft64(k*murmurC1, 31) * murmurC2 }
+
+func murmurMixK2(k uint64) uint64 { return bits.RotateLeft64(k*murmurC2, 33) * murmurC1 }
+
+func murmurFmix(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
@@ -0,0 +1,38 @@
+package set
+
+// packedArray holds unsigned integers of a fixed width of up to 64 bits,
+// packed back to back in words.
+type packedArray struct {
+	words []uint64
+	width uint
+	mask  uint64
+}
+
+func newPackedArray(n uint64, width uint) packedArray {
+	return packedArray{
+		// one spare word, so values straddling the last word stay in range
+		words: make([]uint64, n*uint64(width)/64+1),
+		width: width,
+		mask:  1<<width - 1,
+	}
+}
+
+func (p packedArray) get(i uint64) uint64 {
+	bit := i * uint64(p.width)
+	w, off := bit/64, uint(bit%64)
+	v := p.words[w] >> off
+	if off+p.width > 64 {
+		v |= p.words[w+1] << (64 - off)
+	}
+	return v & p.mask
+}
+
+func (p packedArray) set(i, v uint64) {
+	bit := i * uint64(p.width)
+	w, off := bit/64, uint(bit%64)
+	v &= p.mask
+	p.words[w] = p.words[w]&^(p.mask<<off) | v<<off
+	if off+p.width > 64 {
+		p.words[w+1] = p.words[w+1]&^(p.mask>>(64-off)) | v>>(64-off)
+	}
+}
@@ -10,7 +10,7 @@ var (
 	tchapPatriciaIsListable ListSet    = NewTchapPatricia()
 )
 
-// TchapPatricia is a set of string implemented using a sorted slice of strings.
+// TchapPatricia is a set of string implemented using a patricia trie.
 type TchapPatricia struct {
 	p *patricia.Trie
 }
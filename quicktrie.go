@@ -10,7 +10,7 @@ var (
 	QuicktrieIsListable ListSet    = NewQuicktrie()
 )
 
-// Quicktrie is a set of string implemented using a sorted slice of strings.
+// Quicktrie is a set of string implemented using the trie of package quicktrie.
 type Quicktrie struct {
 	r *trie.Trie
 }
@@ -0,0 +1,348 @@
+package set
+
+import (
+	"github.com/dgryski/go-farm"
+	"math"
+	"sort"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	quotientIsMutable       MutableSet       = NewQuotient(0, 8)
+	quotientIsChecked       CheckedSet       = NewQuotient(0, 8)
+	quotientIsProbabilistic ProbabilisticSet = NewQuotient(0, 8)
+)
+
+const (
+	quotientLoadFactor = 0.9
+
+	// the three metadata bits in the low bits of each slot
+	qfOccupied     = 1 // the slot's quotient has a run somewhere
+	qfContinuation = 2 // the slot continues the run of the slot before
+	qfShifted      = 4 // the slot's remainder is not in its canonical slot
+	qfMetaBits     = 3
+)
+
+// Quotient is a set of string implemented using a quotient filter. The
+// fingerprint of a key is split in a quotient, the slot where it belongs,
+// and a remainder stored in that slot or, on collisions, in the next free
+// ones. The fingerprints are all it keeps, so it may claim to contain keys
+// that were never added, but they are enough to delete keys, double the
+// filter or merge filters without the keys. Keys are counted, not
+// deduplicated: adding a key twice takes deleting it twice.
+type Quotient struct {
+	slots packedArray // remainder<<qfMetaBits | metadata, per slot
+	qbits uint
+	rbits uint
+	n     int
+}
+
+// qfEntry is a fingerprint, its quotient unwrapped past the end of the
+// slots when its run wrapped around.
+type qfEntry struct{ quotient, remainder uint64 }
+
+// NewQuotient creates a Quotient with room for at least capacity keys,
+// each keeping a remainder of remainderBits bits, from 1 to 32.
+func NewQuotient(capacity int, remainderBits uint) *Quotient {
+	if remainderBits < 1 || remainderBits > 32 {
+		panic("set: quotient filter remainders must have 1 to 32 bits")
+	}
+	qbits := uint(1)
+	for float64(uint64(1)<<qbits)*quotientLoadFactor < float64(capacity) {
+		qbits++
+	}
+	if qbits+remainderBits > 64 {
+		panic("set: quotient filter fingerprints must fit in 64 bits")
+	}
+	return newQuotient(qbits, remainderBits)
+}
+
+func newQuotient(qbits, rbits uint) *Quotient {
+	return &Quotient{
+		slots: newPackedArray(1<<qbits, rbits+qfMetaBits),
+		qbits: qbits,
+		rbits: rbits,
+	}
+}
+
+func (qf *Quotient) size() uint64 { return 1 << qf.qbits }
+
+func (qf *Quotient) maxLen() int { return int(float64(qf.size()) * quotientLoadFactor) }
+
+// fingerprint gives the quotient and remainder of a key, from the top
+// qbits+rbits bits of its hash.
+func (qf *Quotient) fingerprint(s string) (quotient, remainder uint64) {
+	f := farm.Hash64([]byte(s)) >> (64 - qf.qbits - qf.rbits)
+	return f >> qf.rbits, f & (1<<qf.rbits - 1)
+}
+
+func (qf *Quotient) meta(i uint64) uint64 { return qf.slots.get(i) & (1<<qfMetaBits - 1) }
+
+func (qf *Quotient) isEmpty(i uint64) bool { return qf.meta(i) == 0 }
+
+func (qf *Quotient) next(i uint64) uint64 { return (i + 1) & (qf.size() - 1) }
+
+func (qf *Quotient) prev(i uint64) uint64 { return (i - 1) & (qf.size() - 1) }
+
+// clusterStart walks back from a filled slot to the first slot of its
+// cluster, the one whose remainder is in its canonical slot.
+func (qf *Quotient) clusterStart(i uint64) uint64 {
+	for qf.meta(i)&qfShifted != 0 {
+		i = qf.prev(i)
+	}
+	return i
+}
+
+// decode gives the fingerprints from the cluster starting at start up to
+// the next empty slot, in slot order.
+func (qf *Quotient) decode(start uint64) []qfEntry {
+	var entries []qfEntry
+	quotient := start
+	for i := start; !qf.isEmpty(i); i = qf.next(i) {
+		v := qf.slots.get(i)
+		if i != start && v&qfContinuation == 0 {
+			// a new run, for the next occupied quotient
+			quotient++
+			for qf.meta(quotient&(qf.size()-1))&qfOccupied == 0 {
+				quotient++
+			}
+		}
+		entries = append(entries, qfEntry{quotient, v >> qfMetaBits})
+	}
+	return entries
+}
+
+// encode rewrites the slots from start with the entries, sorted. The
+// slots of the old entries are cleared first, occupied bits included:
+// the runs of their quotients are all among the entries.
+func (qf *Quotient) encode(start uint64, old int, entries []qfEntry) {
+	mask := qf.size() - 1
+	for i := uint64(0); i < uint64(old); i++ {
+		qf.slots.set((start+i)&mask, 0)
+	}
+	pos := start
+	for j, e := range entries {
+		if e.quotient > pos {
+			pos = e.quotient
+		}
+		v := qf.slots.get(pos&mask)&qfOccupied | e.remainder<<qfMetaBits
+		if j > 0 && entries[j-1].quotient == e.quotient {
+			v |= qfContinuation
+		}
+		if pos != e.quotient {
+			v |= qfShifted
+		}
+		qf.slots.set(pos&mask, v)
+		q := e.quotient & mask
+		qf.slots.set(q, qf.slots.get(q)|qfOccupied)
+		pos++
+	}
+}
+
+// region gives the start of the cluster that holds quotient q, or would,
+// and the fingerprints found from there, for insert and Delete to rewrite.
+func (qf *Quotient) region(q uint64) (uint64, []qfEntry) {
+	if qf.isEmpty(q) {
+		return q, nil
+	}
+	start := qf.clusterStart(q)
+	return start, qf.decode(start)
+}
+
+// unwrap gives quotient q as seen from a cluster starting at start.
+func (qf *Quotient) unwrap(start, q uint64) uint64 {
+	return start + (q-start)&(qf.size()-1)
+}
+
+// insert the fingerprint, next to its duplicates if it has some.
+func (qf *Quotient) insert(q, r uint64) {
+	start, entries := qf.region(q)
+	e := qfEntry{qf.unwrap(start, q), r}
+	i := sort.Search(len(entries), func(i int) bool { return !qfLess(entries[i], e) })
+	old := len(entries)
+	entries = append(entries, qfEntry{})
+	copy(entries[i+1:], entries[i:])
+	entries[i] = e
+	qf.encode(start, old, entries)
+}
+
+func qfLess(a, b qfEntry) bool {
+	return a.quotient < b.quotient || a.quotient == b.quotient && a.remainder < b.remainder
+}
+
+// Add the key to the set, panics if the set is full.
+func (qf *Quotient) Add(s string) {
+	if err := qf.TryAdd(s); err != nil {
+		panic(err)
+	}
+}
+
+// TryAdd adds the key to the set, or returns ErrFull if there is no
+// room left for it.
+func (qf *Quotient) TryAdd(s string) error {
+	q, r := qf.fingerprint(s)
+	if qf.n >= qf.maxLen() {
+		return ErrFull
+	}
+	qf.insert(q, r)
+	qf.n++
+	return nil
+}
+
+// runStart gives the first slot of the run of an occupied quotient q:
+// from the start of its cluster, each occupied quotient before q has a
+// run to skip.
+func (qf *Quotient) runStart(q uint64) uint64 {
+	b := qf.clusterStart(q)
+	s := b
+	for b != q {
+		for s = qf.next(s); qf.meta(s)&qfContinuation != 0; s = qf.next(s) {
+		}
+		for b = qf.next(b); qf.meta(b)&qfOccupied == 0; b = qf.next(b) {
+		}
+	}
+	return s
+}
+
+// has walks the run of quotient q in place, its remainders sorted.
+func (qf *Quotient) has(q, r uint64) bool {
+	if qf.meta(q)&qfOccupied == 0 {
+		return false
+	}
+	i := qf.runStart(q)
+	for {
+		switch rem := qf.slots.get(i) >> qfMetaBits; {
+		case rem == r:
+			return true
+		case rem > r:
+			return false
+		}
+		if i = qf.next(i); qf.meta(i)&qfContinuation == 0 {
+			return false
+		}
+	}
+}
+
+// Contains tells if this key was probably in the set.
+func (qf *Quotient) Contains(s string) bool { return qf.has(qf.fingerprint(s)) }
+
+// Delete the element form this set. Deleting a key that was never added
+// but is a false positive removes the fingerprint of another key.
+func (qf *Quotient) Delete(s string) {
+	q, r := qf.fingerprint(s)
+	if qf.meta(q)&qfOccupied == 0 {
+		return
+	}
+	start, entries := qf.region(q)
+	want := qfEntry{qf.unwrap(start, q), r}
+	for i, e := range entries {
+		if e != want {
+			continue
+		}
+		old := len(entries)
+		entries = append(entries[:i], entries[i+1:]...)
+		qf.encode(start, old, entries)
+		qf.n--
+		return
+	}
+}
+
+// IsEmpty tells if this set is empty.
+func (qf *Quotient) IsEmpty() bool { return qf.n == 0 }
+
+// Len is the length of this set.
+func (qf *Quotient) Len() int { return qf.n }
+
+// EstimatedFalsePositiveRate is the probability that Contains is true
+// for a key that was never added, given the slots filled so far.
+func (qf *Quotient) EstimatedFalsePositiveRate() float64 {
+	return qf.falsePositiveRate(float64(qf.n) / float64(qf.size()))
+}
+
+func (qf *Quotient) falsePositiveRate(load float64) float64 {
+	return 1 - math.Exp(-load/float64(uint64(1)<<qf.rbits))
+}
+
+// Capacity is the number of keys this set can hold before it is full.
+func (qf *Quotient) Capacity() int { return qf.maxLen() }
+
+// FalsePositiveRate is the false positive rate of this set once it holds
+// Capacity keys.
+func (qf *Quotient) FalsePositiveRate() float64 { return qf.falsePositiveRate(quotientLoadFactor) }
+
+// IsExact is false, a Quotient may claim to contain keys it never saw.
+func (qf *Quotient) IsExact() bool { return false }
+
+// fingerprints gives all the fingerprints of the filter, as a quotient
+// and a remainder.
+func (qf *Quotient) fingerprints() []qfEntry {
+	all := make([]qfEntry, 0, qf.n)
+	if qf.n == 0 {
+		return all
+	}
+	// no cluster wraps around an empty slot, start after one
+	empty := uint64(0)
+	for !qf.isEmpty(empty) {
+		empty++
+	}
+	mask := qf.size() - 1
+	for i := uint64(1); i <= qf.size(); {
+		slot := (empty + i) & mask
+		if qf.isEmpty(slot) {
+			i++
+			continue
+		}
+		entries := qf.decode(slot)
+		for _, e := range entries {
+			all = append(all, qfEntry{e.quotient & mask, e.remainder})
+		}
+		i += uint64(len(entries))
+	}
+	return all
+}
+
+// rehash gives a new filter of qbits quotient bits, holding the
+// fingerprints of the filters, which must all have qbits+rbits bits.
+func rehash(qbits, rbits uint, filters ...*Quotient) *Quotient {
+	out := newQuotient(qbits, rbits)
+	for _, qf := range filters {
+		for _, e := range qf.fingerprints() {
+			f := e.quotient<<qf.rbits | e.remainder
+			out.insert(f>>rbits, f&(1<<rbits-1))
+			out.n++
+		}
+	}
+	return out
+}
+
+// Resize gives a new filter with twice the slots of this one, holding
+// the same keys. Each fingerprint gives a bit of its remainder to its
+// quotient, so the false positive rate at a given load doubles. It
+// returns ErrFull if the remainders have a single bit left.
+func (qf *Quotient) Resize() (*Quotient, error) {
+	if qf.rbits == 1 {
+		return nil, ErrFull
+	}
+	return rehash(qf.qbits+1, qf.rbits-1, qf), nil
+}
+
+// Merge this filter and other in a new filter large enough to hold the
+// keys of both. Both must have fingerprints of the same number of bits,
+// or ErrIncompatible is returned.
+func (qf *Quotient) Merge(other *Quotient) (*Quotient, error) {
+	bits := qf.qbits + qf.rbits
+	if other.qbits+other.rbits != bits {
+		return nil, ErrIncompatible
+	}
+	qbits := qf.qbits
+	if other.qbits > qbits {
+		qbits = other.qbits
+	}
+	for float64(uint64(1)<<qbits)*quotientLoadFactor < float64(qf.n+other.n) {
+		qbits++
+	}
+	if qbits >= bits {
+		return nil, ErrFull
+	}
+	return rehash(qbits, bits-qbits, qf, other), nil
+}
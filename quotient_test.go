@@ -0,0 +1,152 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"strconv"
+	"testing"
+)
+
+func TestQuotient_Empty(t *testing.T) { setTest(t, set.NewQuotient(100, 16), []string{}) }
+func TestQuotient_One(t *testing.T)   { setTest(t, set.NewQuotient(100, 16), []string{"A"}) }
+func TestQuotient_Many(t *testing.T)  { setTest(t, set.NewQuotient(100, 16), []string{"A", "B", "C"}) }
+func TestQuotient_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return set.NewQuotient(100, 16) })
+}
+
+func TestQuotient_Delete(t *testing.T) {
+	// few quotient bits make long clusters that wrap around
+	q := set.NewQuotient(len(web2), 6)
+	for _, k := range web2 {
+		q.Add(k)
+	}
+	for _, k := range web2[:len(web2)/2] {
+		q.Delete(k)
+	}
+	fpTest(t, q, web2[:len(web2)/2], q.EstimatedFalsePositiveRate())
+	for _, k := range web2[len(web2)/2:] {
+		if !q.Contains(k) {
+			t.Fatalf("should still contain %q", k)
+		}
+	}
+	for _, k := range web2[len(web2)/2:] {
+		q.Delete(k)
+	}
+	if !q.IsEmpty() {
+		t.Fatalf("should be empty, has %d keys", q.Len())
+	}
+}
+
+func TestQuotient_SharedFingerprints(t *testing.T) {
+	// short fingerprints make many keys share one, each must be kept
+	q := set.NewQuotient(20000, 4)
+	var keys []string
+	for i := 0; i < 18000; i++ {
+		keys = append(keys, strconv.Itoa(i))
+	}
+	for _, k := range keys {
+		q.Add(k)
+	}
+	if q.Len() != len(keys) {
+		t.Fatalf("want %d keys, got %d", len(keys), q.Len())
+	}
+	for _, k := range keys[:len(keys)/2] {
+		q.Delete(k)
+	}
+	for _, k := range keys[len(keys)/2:] {
+		if !q.Contains(k) {
+			t.Fatalf("should still contain %q", k)
+		}
+	}
+
+	// the duplicates make it through a resize
+	r, err := q.Resize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != len(keys)/2 {
+		t.Fatalf("want %d keys after resizing, got %d", len(keys)/2, r.Len())
+	}
+	for _, k := range keys[len(keys)/2:] {
+		r.Delete(k)
+	}
+	if !r.IsEmpty() {
+		t.Fatalf("should be empty, has %d keys", r.Len())
+	}
+}
+
+func TestQuotient_Full(t *testing.T) {
+	q := set.NewQuotient(10, 8)
+
+	var err error
+	var added []string
+	for _, k := range web2 {
+		if err = q.TryAdd(k); err != nil {
+			break
+		}
+		added = append(added, k)
+	}
+	if err != set.ErrFull {
+		t.Fatalf("want %v, got %v", set.ErrFull, err)
+	}
+	for _, k := range added {
+		if !q.Contains(k) {
+			t.Fatalf("should contain %q", k)
+		}
+	}
+}
+
+func TestQuotient_Resize(t *testing.T) {
+	q := set.NewQuotient(10, 12)
+	for _, k := range web2[:9] {
+		q.Add(k)
+	}
+	for i := 0; i < 5; i++ {
+		var err error
+		if q, err = q.Resize(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// fill what room is left, whatever the size of the word list
+	keys := web2[9:]
+	if room := q.Capacity() - q.Len(); len(keys) > room {
+		keys = keys[:room]
+	}
+	for _, k := range keys {
+		q.Add(k)
+	}
+	for _, k := range web2[:9+len(keys)] {
+		if !q.Contains(k) {
+			t.Fatalf("should contain %q", k)
+		}
+	}
+
+	small := set.NewQuotient(1, 1)
+	if _, err := small.Resize(); err != set.ErrFull {
+		t.Fatalf("want %v, got %v", set.ErrFull, err)
+	}
+}
+
+func TestQuotient_Merge(t *testing.T) {
+	half, quarter := len(web2)/2, len(web2)/4
+	a, b := set.NewQuotient(quarter, 16), set.NewQuotient(quarter, 16)
+	for _, k := range web2[:quarter] {
+		a.Add(k)
+	}
+	for _, k := range web2[quarter:half] {
+		b.Add(k)
+	}
+	ab, err := a.Merge(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range web2[:half] {
+		if !ab.Contains(k) {
+			t.Fatalf("should contain %q", k)
+		}
+	}
+	fpTest(t, ab, web2[half:], ab.EstimatedFalsePositiveRate())
+
+	if _, err := a.Merge(set.NewQuotient(half, 8)); err != set.ErrIncompatible {
+		t.Fatalf("want %v, got %v", set.ErrIncompatible, err)
+	}
+}
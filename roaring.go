@@ -0,0 +1,636 @@
+package set
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"sort"
+)
+
+const (
+	// containers of at most this many values are arrays, larger ones are
+	// bitmaps, unless runs take less room
+	roaringArrayMax = 4096
+	roaringWords    = 1 << 16 / 64
+
+	// cookies of the portable format, with or without run containers
+	roaringCookieNoRuns = 12346
+	roaringCookie       = 12347
+	// below this many containers, a stream with runs has no offsets
+	roaringNoOffsetThreshold = 4
+)
+
+// Bitmap is a compressed set of uint32 implemented as a Roaring bitmap.
+// Values are split on their high 16 bits into containers, each holding the
+// low 16 bits of its values as a sorted array, a bitmap of 2^16 bits or a
+// list of runs, whichever is smaller. Set operations work a container at
+// a time, on whole words for bitmaps.
+type Bitmap struct {
+	keys       []uint16 // sorted high bits of the values
+	containers []roaringContainer
+}
+
+// NewBitmap creates an empty Bitmap.
+func NewBitmap() *Bitmap { return &Bitmap{} }
+
+// roaringContainer holds the low 16 bits of the values of a Bitmap
+// sharing the same high bits.
+type roaringContainer interface {
+	card() int
+	contains(v uint16) bool
+	// add and remove may convert the container, giving the one to use.
+	add(v uint16) roaringContainer
+	remove(v uint16) roaringContainer
+	each(f func(v uint16))
+	clone() roaringContainer
+	// bitmap is the container as a bitmap, which may be itself.
+	bitmap() *bitmapContainer
+}
+
+type arrayContainer struct{ vals []uint16 }
+
+type bitmapContainer struct {
+	words [roaringWords]uint64
+	n     int
+}
+
+type runContainer struct{ runs []run16 }
+
+// run16 is the run of values from start to last, included.
+type run16 struct{ start, last uint16 }
+
+func (ac *arrayContainer) card() int { return len(ac.vals) }
+
+func (ac *arrayContainer) find(v uint16) int {
+	return sort.Search(len(ac.vals), func(i int) bool { return ac.vals[i] >= v })
+}
+
+func (ac *arrayContainer) contains(v uint16) bool {
+	i := ac.find(v)
+	return i < len(ac.vals) && ac.vals[i] == v
+}
+
+func (ac *arrayContainer) add(v uint16) roaringContainer {
+	i := ac.find(v)
+	if i < len(ac.vals) && ac.vals[i] == v {
+		return ac
+	}
+	if len(ac.vals) == roaringArrayMax {
+		bc := ac.bitmap()
+		return bc.add(v)
+	}
+	ac.vals = append(ac.vals, 0)
+	copy(ac.vals[i+1:], ac.vals[i:])
+	ac.vals[i] = v
+	return ac
+}
+
+func (ac *arrayContainer) remove(v uint16) roaringContainer {
+	if i := ac.find(v); i < len(ac.vals) && ac.vals[i] == v {
+		ac.vals = append(ac.vals[:i], ac.vals[i+1:]...)
+	}
+	return ac
+}
+
+func (ac *arrayContainer) each(f func(v uint16)) {
+	for _, v := range ac.vals {
+		f(v)
+	}
+}
+
+func (ac *arrayContainer) clone() roaringContainer {
+	return &arrayContainer{vals: append([]uint16(nil), ac.vals...)}
+}
+
+func (ac *arrayContainer) bitmap() *bitmapContainer {
+	bc := &bitmapContainer{n: len(ac.vals)}
+	for _, v := range ac.vals {
+		bc.words[v/64] |= 1 << (v % 64)
+	}
+	return bc
+}
+
+// filter is a new array of the values for which other.contains is keep.
+func (ac *arrayContainer) filter(other roaringContainer, keep bool) roaringContainer {
+	out := &arrayContainer{}
+	for _, v := range ac.vals {
+		if other.contains(v) == keep {
+			out.vals = append(out.vals, v)
+		}
+	}
+	return out
+}
+
+func (bc *bitmapContainer) card() int { return bc.n }
+
+func (bc *bitmapContainer) contains(v uint16) bool { return bc.words[v/64]&(1<<(v%64)) != 0 }
+
+func (bc *bitmapContainer) add(v uint16) roaringContainer {
+	if !bc.contains(v) {
+		bc.words[v/64] |= 1 << (v % 64)
+		bc.n++
+	}
+	return bc
+}
+
+func (bc *bitmapContainer) remove(v uint16) roaringContainer {
+	if bc.contains(v) {
+		bc.words[v/64] &^= 1 << (v % 64)
+		bc.n--
+	}
+	if bc.n <= roaringArrayMax {
+		return bc.array()
+	}
+	return bc
+}
+
+func (bc *bitmapContainer) each(f func(v uint16)) {
+	for i, w := range bc.words {
+		for ; w != 0; w &= w - 1 {
+			f(uint16(i*64 + bits.TrailingZeros64(w)))
+		}
+	}
+}
+
+func (bc *bitmapContainer) clone() roaringContainer {
+	out := *bc
+	return &out
+}
+
+func (bc *bitmapContainer) bitmap() *bitmapContainer { return bc }
+
+func (bc *bitmapContainer) array() *arrayContainer {
+	ac := &arrayContainer{vals: make([]uint16, 0, bc.n)}
+	bc.each(func(v uint16) { ac.vals = append(ac.vals, v) })
+	return ac
+}
+
+// canonical is the container as an array if it is small enough.
+func (bc *bitmapContainer) canonical() roaringContainer {
+	if bc.n <= roaringArrayMax {
+		return bc.array()
+	}
+	return bc
+}
+
+func (rc *runContainer) card() int {
+	n := 0
+	for _, r := range rc.runs {
+		n += int(r.last-r.start) + 1
+	}
+	return n
+}
+
+func (rc *runContainer) contains(v uint16) bool {
+	i := sort.Search(len(rc.runs), func(i int) bool { return rc.runs[i].last >= v })
+	return i < len(rc.runs) && rc.runs[i].start <= v
+}
+
+// Runs are only made by RunOptimize, adding or removing converts them
+// back to arrays or bitmaps.
+func (rc *runContainer) add(v uint16) roaringContainer {
+	if rc.contains(v) {
+		return rc
+	}
+	return rc.bitmap().canonical().add(v)
+}
+
+func (rc *runContainer) remove(v uint16) roaringContainer {
+	if !rc.contains(v) {
+		return rc
+	}
+	return rc.bitmap().canonical().remove(v)
+}
+
+func (rc *runContainer) each(f func(v uint16)) {
+	for _, r := range rc.runs {
+		for v := uint32(r.start); v <= uint32(r.last); v++ {
+			f(uint16(v))
+		}
+	}
+}
+
+func (rc *runContainer) clone() roaringContainer {
+	return &runContainer{runs: append([]run16(nil), rc.runs...)}
+}
+
+func (rc *runContainer) bitmap() *bitmapContainer {
+	bc := &bitmapContainer{}
+	for _, r := range rc.runs {
+		for v := uint32(r.start); v <= uint32(r.last); v++ {
+			bc.words[v/64] |= 1 << (v % 64)
+		}
+		bc.n += int(r.last-r.start) + 1
+	}
+	return bc
+}
+
+// toRuns gives the runs of the values of a container.
+func toRuns(c roaringContainer) []run16 {
+	var runs []run16
+	c.each(func(v uint16) {
+		if n := len(runs); n > 0 && uint32(runs[n-1].last)+1 == uint32(v) {
+			runs[n-1].last = v
+		} else {
+			runs = append(runs, run16{v, v})
+		}
+	})
+	return runs
+}
+
+const (
+	opAnd = iota
+	opOr
+	opAndNot
+	opXor
+)
+
+// wordsOp combines two bitmaps a word at a time.
+func wordsOp(a, b *bitmapContainer, op int) roaringContainer {
+	out := &bitmapContainer{}
+	switch op {
+	case opAnd:
+		for i := range out.words {
+			out.words[i] = a.words[i] & b.words[i]
+		}
+	case opOr:
+		for i := range out.words {
+			out.words[i] = a.words[i] | b.words[i]
+		}
+	case opAndNot:
+		for i := range out.words {
+			out.words[i] = a.words[i] &^ b.words[i]
+		}
+	case opXor:
+		for i := range out.words {
+			out.words[i] = a.words[i] ^ b.words[i]
+		}
+	}
+	for _, w := range out.words {
+		out.n += bits.OnesCount64(w)
+	}
+	return out.canonical()
+}
+
+// mergeArrays merges two sorted arrays, keeping the values found in both
+// only if both is true.
+func mergeArrays(a, b []uint16, both bool) []uint16 {
+	out := make([]uint16, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case a[i] > b[j]:
+			out = append(out, b[j])
+			j++
+		default:
+			if both {
+				out = append(out, a[i])
+			}
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
+
+func containerOp(a, b roaringContainer, op int) roaringContainer {
+	x, aIsArray := a.(*arrayContainer)
+	y, bIsArray := b.(*arrayContainer)
+	switch {
+	case op == opAnd && aIsArray:
+		return x.filter(b, true)
+	case op == opAnd && bIsArray:
+		return y.filter(a, true)
+	case op == opAndNot && aIsArray:
+		return x.filter(b, false)
+	case (op == opOr || op == opXor) && aIsArray && bIsArray:
+		vals := mergeArrays(x.vals, y.vals, op == opOr)
+		if len(vals) > roaringArrayMax {
+			return (&arrayContainer{vals: vals}).bitmap()
+		}
+		return &arrayContainer{vals: vals}
+	}
+	return wordsOp(a.bitmap(), b.bitmap(), op)
+}
+
+func (b *Bitmap) find(key uint16) int {
+	return sort.Search(len(b.keys), func(i int) bool { return b.keys[i] >= key })
+}
+
+// Add the value to the bitmap.
+func (b *Bitmap) Add(x uint32) {
+	key := uint16(x >> 16)
+	i := b.find(key)
+	if i == len(b.keys) || b.keys[i] != key {
+		b.keys = append(b.keys, 0)
+		copy(b.keys[i+1:], b.keys[i:])
+		b.keys[i] = key
+		b.containers = append(b.containers, nil)
+		copy(b.containers[i+1:], b.containers[i:])
+		b.containers[i] = &arrayContainer{}
+	}
+	b.containers[i] = b.containers[i].add(uint16(x))
+}
+
+// Remove the value from the bitmap.
+func (b *Bitmap) Remove(x uint32) {
+	key := uint16(x >> 16)
+	i := b.find(key)
+	if i == len(b.keys) || b.keys[i] != key {
+		return
+	}
+	b.containers[i] = b.containers[i].remove(uint16(x))
+	if b.containers[i].card() == 0 {
+		b.keys = append(b.keys[:i], b.keys[i+1:]...)
+		b.containers = append(b.containers[:i], b.containers[i+1:]...)
+	}
+}
+
+// Contains tells if the value is in the bitmap.
+func (b *Bitmap) Contains(x uint32) bool {
+	key := uint16(x >> 16)
+	i := b.find(key)
+	return i < len(b.keys) && b.keys[i] == key && b.containers[i].contains(uint16(x))
+}
+
+// Cardinality is the number of values in the bitmap.
+func (b *Bitmap) Cardinality() uint64 {
+	n := uint64(0)
+	for _, c := range b.containers {
+		n += uint64(c.card())
+	}
+	return n
+}
+
+// IsEmpty tells if the bitmap is empty.
+func (b *Bitmap) IsEmpty() bool { return len(b.keys) == 0 }
+
+// Each calls f with every value of the bitmap, in increasing order.
+func (b *Bitmap) Each(f func(x uint32)) {
+	for i, c := range b.containers {
+		high := uint32(b.keys[i]) << 16
+		c.each(func(v uint16) { f(high | uint32(v)) })
+	}
+}
+
+// ToArray gives the values of the bitmap, in increasing order.
+func (b *Bitmap) ToArray() []uint32 {
+	out := make([]uint32, 0, b.Cardinality())
+	b.Each(func(x uint32) { out = append(out, x) })
+	return out
+}
+
+// RunOptimize turns the containers that take less room as runs into run
+// containers, and the others back to arrays or bitmaps.
+func (b *Bitmap) RunOptimize() {
+	for i, c := range b.containers {
+		runs := toRuns(c)
+		size := 2 * c.card()
+		if c.card() > roaringArrayMax {
+			size = 8 * roaringWords
+		}
+		if 2+4*len(runs) < size {
+			b.containers[i] = &runContainer{runs: runs}
+		} else if _, ok := c.(*runContainer); ok {
+			b.containers[i] = c.bitmap().canonical()
+		}
+	}
+}
+
+// combine applies op to the containers of b and other sharing a key. The
+// containers found only in b are kept if keepB, those only in other if
+// keepOther.
+func (b *Bitmap) combine(other *Bitmap, op int, keepB, keepOther bool) *Bitmap {
+	out := &Bitmap{}
+	push := func(key uint16, c roaringContainer) {
+		if c.card() > 0 {
+			out.keys = append(out.keys, key)
+			out.containers = append(out.containers, c)
+		}
+	}
+	i, j := 0, 0
+	for i < len(b.keys) && j < len(other.keys) {
+		switch {
+		case b.keys[i] < other.keys[j]:
+			if keepB {
+				push(b.keys[i], b.containers[i].clone())
+			}
+			i++
+		case b.keys[i] > other.keys[j]:
+			if keepOther {
+				push(other.keys[j], other.containers[j].clone())
+			}
+			j++
+		default:
+			push(b.keys[i], containerOp(b.containers[i], other.containers[j], op))
+			i++
+			j++
+		}
+	}
+	for ; keepB && i < len(b.keys); i++ {
+		push(b.keys[i], b.containers[i].clone())
+	}
+	for ; keepOther && j < len(other.keys); j++ {
+		push(other.keys[j], other.containers[j].clone())
+	}
+	return out
+}
+
+// And is a new bitmap of the values in both b and other.
+func (b *Bitmap) And(other *Bitmap) *Bitmap { return b.combine(other, opAnd, false, false) }
+
+// Or is a new bitmap of the values in b or in other.
+func (b *Bitmap) Or(other *Bitmap) *Bitmap { return b.combine(other, opOr, true, true) }
+
+// AndNot is a new bitmap of the values in b but not in other.
+func (b *Bitmap) AndNot(other *Bitmap) *Bitmap { return b.combine(other, opAndNot, true, false) }
+
+// Xor is a new bitmap of the values in either b or other but not both.
+func (b *Bitmap) Xor(other *Bitmap) *Bitmap { return b.combine(other, opXor, true, true) }
+
+// MarshalBinary encodes the bitmap in the portable Roaring format, read
+// by the Roaring libraries of other languages.
+func (b *Bitmap) MarshalBinary() ([]byte, error) {
+	size := len(b.keys)
+	hasRuns := false
+	for _, c := range b.containers {
+		if _, ok := c.(*runContainer); ok {
+			hasRuns = true
+		}
+	}
+
+	var buf []byte
+	if hasRuns {
+		buf = binary.LittleEndian.AppendUint32(buf, roaringCookie|uint32(size-1)<<16)
+		flags := make([]byte, (size+7)/8)
+		for i, c := range b.containers {
+			if _, ok := c.(*runContainer); ok {
+				flags[i/8] |= 1 << (i % 8)
+			}
+		}
+		buf = append(buf, flags...)
+	} else {
+		buf = binary.LittleEndian.AppendUint32(buf, roaringCookieNoRuns)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(size))
+	}
+	for i, c := range b.containers {
+		buf = binary.LittleEndian.AppendUint16(buf, b.keys[i])
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(c.card()-1))
+	}
+	if !hasRuns || size >= roaringNoOffsetThreshold {
+		offset := len(buf) + 4*size
+		for _, c := range b.containers {
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(offset))
+			offset += containerSize(c)
+		}
+	}
+	for _, c := range b.containers {
+		switch c := c.(type) {
+		case *arrayContainer:
+			for _, v := range c.vals {
+				buf = binary.LittleEndian.AppendUint16(buf, v)
+			}
+		case *bitmapContainer:
+			for _, w := range c.words {
+				buf = binary.LittleEndian.AppendUint64(buf, w)
+			}
+		case *runContainer:
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(len(c.runs)))
+			for _, r := range c.runs {
+				buf = binary.LittleEndian.AppendUint16(buf, r.start)
+				buf = binary.LittleEndian.AppendUint16(buf, r.last-r.start)
+			}
+		}
+	}
+	return buf, nil
+}
+
+// containerSize is the size of a container in the portable format.
+func containerSize(c roaringContainer) int {
+	switch c := c.(type) {
+	case *arrayContainer:
+		return 2 * len(c.vals)
+	case *runContainer:
+		return 2 + 4*len(c.runs)
+	}
+	return 8 * roaringWords
+}
+
+// UnmarshalBinary decodes a bitmap in the portable Roaring format.
+func (b *Bitmap) UnmarshalBinary(data []byte) error {
+	r := roaringReader{data: data}
+	cookie := r.uint32()
+	var size int
+	var runFlags []byte
+	switch {
+	case cookie&0xffff == roaringCookie:
+		size = int(cookie>>16) + 1
+		runFlags = r.bytes((size + 7) / 8)
+	case cookie == roaringCookieNoRuns:
+		size = int(r.uint32())
+		if size > 1<<16 {
+			return ErrMalformed
+		}
+	default:
+		return ErrMalformed
+	}
+	if r.bad || len(r.data) < 4*size {
+		return ErrMalformed
+	}
+
+	out := Bitmap{keys: make([]uint16, size), containers: make([]roaringContainer, size)}
+	cards := make([]int, size)
+	for i := range out.keys {
+		out.keys[i] = r.uint16()
+		cards[i] = int(r.uint16()) + 1
+		if i > 0 && out.keys[i] <= out.keys[i-1] {
+			return ErrMalformed
+		}
+	}
+	hasOffsets := runFlags == nil || size >= roaringNoOffsetThreshold
+	if hasOffsets {
+		r.bytes(4 * size)
+	}
+	for i := range out.containers {
+		isRun := runFlags != nil && runFlags[i/8]&(1<<(i%8)) != 0
+		c, ok := r.container(cards[i], isRun)
+		if !ok {
+			return ErrMalformed
+		}
+		out.containers[i] = c
+	}
+	if r.bad || len(r.data) != 0 {
+		return ErrMalformed
+	}
+	*b = out
+	return nil
+}
+
+// roaringReader reads little endian values, remembering if it ran out of
+// data.
+type roaringReader struct {
+	data []byte
+	bad  bool
+}
+
+func (r *roaringReader) bytes(n int) []byte {
+	if r.bad || len(r.data) < n {
+		r.bad = true
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *roaringReader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *roaringReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// container reads a container of card values, telling if it is valid.
+func (r *roaringReader) container(card int, isRun bool) (roaringContainer, bool) {
+	switch {
+	case isRun:
+		rc := &runContainer{runs: make([]run16, r.uint16())}
+		n := 0
+		for i := range rc.runs {
+			start, length := r.uint16(), r.uint16()
+			if uint32(start)+uint32(length) > 0xffff || i > 0 && start <= rc.runs[i-1].last {
+				return nil, false
+			}
+			rc.runs[i] = run16{start, start + length}
+			n += int(length) + 1
+		}
+		return rc, !r.bad && n == card
+	case card <= roaringArrayMax:
+		ac := &arrayContainer{vals: make([]uint16, card)}
+		for i := range ac.vals {
+			ac.vals[i] = r.uint16()
+			if i > 0 && ac.vals[i] <= ac.vals[i-1] {
+				return nil, false
+			}
+		}
+		return ac, !r.bad
+	}
+	bc := &bitmapContainer{}
+	for i := range bc.words {
+		if b := r.bytes(8); b != nil {
+			bc.words[i] = binary.LittleEndian.Uint64(b)
+		}
+		bc.n += bits.OnesCount64(bc.words[i])
+	}
+	return bc, !r.bad && bc.n == card
+}
@@ -0,0 +1,190 @@
+package set_test
+
+import (
+	"bytes"
+	"github.com/aybabtme/set"
+	"reflect"
+	"testing"
+)
+
+func bitmapOf(vals ...uint32) *set.Bitmap {
+	b := set.NewBitmap()
+	for _, v := range vals {
+		b.Add(v)
+	}
+	return b
+}
+
+// spread gives n values over a few containers, dense enough for some of
+// them to be bitmaps.
+func spread(n int, step uint32) []uint32 {
+	vals := make([]uint32, n)
+	for i := range vals {
+		vals[i] = uint32(i) * step
+	}
+	return vals
+}
+
+func checkBitmap(t *testing.T, b *set.Bitmap, want []uint32) {
+	got := b.ToArray()
+	if len(got) != len(want) || b.Cardinality() != uint64(len(want)) {
+		t.Fatalf("want %d values, got %d (cardinality %d)", len(want), len(got), b.Cardinality())
+	}
+	if len(want) > 0 && !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v..., got %v...", want[:1], got[:1])
+	}
+	for _, v := range want {
+		if !b.Contains(v) {
+			t.Fatalf("should contain %d", v)
+		}
+	}
+}
+
+func TestBitmap_AddRemove(t *testing.T) {
+	b := set.NewBitmap()
+	if !b.IsEmpty() {
+		t.Fatalf("should be empty")
+	}
+	// enough values in a container to make it a bitmap, and back
+	vals := spread(10000, 3)
+	for _, v := range vals {
+		b.Add(v)
+	}
+	checkBitmap(t, b, vals)
+	for _, v := range vals[:9000] {
+		b.Remove(v)
+	}
+	checkBitmap(t, b, vals[9000:])
+	if b.Contains(vals[0]) {
+		t.Fatalf("should not contain %d after removal", vals[0])
+	}
+	for _, v := range vals[9000:] {
+		b.Remove(v)
+	}
+	if !b.IsEmpty() {
+		t.Fatalf("should be empty after removing everything")
+	}
+}
+
+func TestBitmap_Operations(t *testing.T) {
+	a := spread(20000, 3) // multiples of 3
+	b := spread(20000, 5) // multiples of 5
+	in := func(v, step uint32, n int) bool { return v%step == 0 && v/step < uint32(n) }
+	var and, or, andNot, xor []uint32
+	for v := uint32(0); v < 100000; v++ {
+		inA, inB := in(v, 3, len(a)), in(v, 5, len(b))
+		if inA && inB {
+			and = append(and, v)
+		}
+		if inA || inB {
+			or = append(or, v)
+		}
+		if inA && !inB {
+			andNot = append(andNot, v)
+		}
+		if inA != inB {
+			xor = append(xor, v)
+		}
+	}
+	for _, optimize := range []bool{false, true} {
+		ba, bb := bitmapOf(a...), bitmapOf(b...)
+		if optimize {
+			ba.RunOptimize()
+			bb.RunOptimize()
+		}
+		checkBitmap(t, ba.And(bb), and)
+		checkBitmap(t, ba.Or(bb), or)
+		checkBitmap(t, ba.AndNot(bb), andNot)
+		checkBitmap(t, ba.Xor(bb), xor)
+		// the operands are left alone
+		checkBitmap(t, ba, a)
+		checkBitmap(t, bb, b)
+	}
+}
+
+func TestBitmap_RunOptimize(t *testing.T) {
+	var vals []uint32
+	for v := uint32(1000); v < 200000; v++ {
+		vals = append(vals, v)
+	}
+	b := bitmapOf(vals...)
+	before, _ := b.MarshalBinary()
+	b.RunOptimize()
+	after, _ := b.MarshalBinary()
+	if len(after) >= len(before)/100 {
+		t.Fatalf("runs should shrink the encoding, from %d to %d bytes", len(before), len(after))
+	}
+	checkBitmap(t, b, vals)
+	b.Remove(1500)
+	b.Add(0)
+	checkBitmap(t, b, append([]uint32{0}, append(vals[:500:500], vals[501:]...)...))
+}
+
+// encoded by the Roaring reference implementation
+var bitmapVectors = []struct {
+	vals []uint32
+	runs bool
+	data []byte
+}{
+	{[]uint32{1, 2, 3, 1000, 1 << 20}, false, []byte{
+		0x3a, 0x30, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x00,
+		0x10, 0x00, 0x00, 0x00, 0x18, 0x00, 0x00, 0x00, 0x20, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x02, 0x00, 0x03, 0x00, 0xe8, 0x03, 0x00, 0x00,
+	}},
+	{[]uint32{10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 1 << 17}, true, []byte{
+		0x3b, 0x30, 0x01, 0x00, 0x01, 0x00, 0x00, 0x09, 0x00, 0x02, 0x00, 0x00,
+		0x00, 0x01, 0x00, 0x0a, 0x00, 0x09, 0x00, 0x00, 0x00,
+	}},
+}
+
+func TestBitmap_Portable(t *testing.T) {
+	for _, v := range bitmapVectors {
+		b := bitmapOf(v.vals...)
+		if v.runs {
+			b.RunOptimize()
+		}
+		data, err := b.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, v.data) {
+			t.Errorf("%v: want % x, got % x", v.vals, v.data, data)
+		}
+		var out set.Bitmap
+		if err := out.UnmarshalBinary(v.data); err != nil {
+			t.Fatal(err)
+		}
+		checkBitmap(t, &out, v.vals)
+	}
+
+	b := bitmapOf(spread(100000, 7)...)
+	b.RunOptimize()
+	data, _ := b.MarshalBinary()
+	var out set.Bitmap
+	if err := out.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	checkBitmap(t, &out, b.ToArray())
+
+	for _, bad := range [][]byte{
+		{},
+		{0x3a, 0x30, 0x00},
+		bitmapVectors[0].data[:len(bitmapVectors[0].data)-1],
+		append(append([]byte(nil), bitmapVectors[1].data...), 0),
+		{0x39, 0x30, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	} {
+		if err := out.UnmarshalBinary(bad); err != set.ErrMalformed {
+			t.Errorf("% x: want %v, got %v", bad, set.ErrMalformed, err)
+		}
+	}
+}
+
+func TestInterner_Bitmap(t *testing.T) {
+	in := set.NewInterner(0)
+	a := in.Intern(setFromList(web2[:60]))
+	b := in.Intern(setFromList(web2[40:]))
+	got := in.Strings(a.And(b))
+	listableTest(t, setFromList(got), append([]string(nil), web2[40:60]...))
+	got = in.Strings(a.Or(b))
+	listableTest(t, setFromList(got), append([]string(nil), web2...))
+}
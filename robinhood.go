@@ -0,0 +1,274 @@
+package set
+
+const (
+	robinHoodMinSize = 8
+	// the table grows once 7/8 of its slots are taken
+	robinHoodMaxLoadNum = 7
+	robinHoodMaxLoadDen = 8
+)
+
+// digestStore holds the digests of the keys of a hash based set.
+type digestStore interface {
+	// add the digest, telling if it was not there yet.
+	add(d uint64) bool
+	has(d uint64) bool
+	remove(d uint64)
+	len() int
+	// each calls f with every digest.
+	each(f func(d uint64))
+	// fresh is an empty store of the same kind, for n digests.
+	fresh(n int) digestStore
+}
+
+// entryStore holds digests with an entry each, the index of their key in
+// the arena of a KeyedHash.
+type entryStore interface {
+	// put adds the digest with the entry e, giving the entry kept with it
+	// and telling if it was not there yet.
+	put(d uint64, e uint32) (uint32, bool)
+	has(d uint64) bool
+	// take removes the digest, giving the entry kept with it.
+	take(d uint64) (uint32, bool)
+	len() int
+	// remap replaces every entry e by remap[e].
+	remap(remap []uint32)
+}
+
+// mapStore is a digestStore in a Go map.
+type mapStore map[uint64]struct{}
+
+func (m mapStore) add(d uint64) bool {
+	if _, ok := m[d]; ok {
+		return false
+	}
+	m[d] = q
+	return true
+}
+
+func (m mapStore) has(d uint64) bool { _, ok := m[d]; return ok }
+func (m mapStore) remove(d uint64)   { delete(m, d) }
+func (m mapStore) len() int          { return len(m) }
+
+func (m mapStore) each(f func(d uint64)) {
+	for d := range m {
+		f(d)
+	}
+}
+
+func (m mapStore) fresh(n int) digestStore { return make(mapStore, n) }
+
+// entryMap is an entryStore in a Go map.
+type entryMap map[uint64]uint32
+
+func (m entryMap) put(d uint64, e uint32) (uint32, bool) {
+	if kept, ok := m[d]; ok {
+		return kept, false
+	}
+	m[d] = e
+	return e, true
+}
+
+func (m entryMap) has(d uint64) bool { _, ok := m[d]; return ok }
+func (m entryMap) len() int          { return len(m) }
+
+func (m entryMap) take(d uint64) (uint32, bool) {
+	e, ok := m[d]
+	delete(m, d)
+	return e, ok
+}
+
+func (m entryMap) remap(remap []uint32) {
+	for d, e := range m {
+		m[d] = remap[e]
+	}
+}
+
+// robinHood is a digestStore in a flat open addressing table with Robin
+// Hood probing: a digest being inserted takes the slot of any digest
+// closer to its home slot, so probe lengths stay short and even, and a
+// lookup stops as soon as it meets a digest closer to home than it would
+// be. Digests are already hashes, their low bits are their home slot.
+//
+// 0 marks an empty slot, the digest 0 is kept aside in hasZero. As an
+// entryStore, it keeps the entries in a slice beside the slots.
+type robinHood struct {
+	slots     []uint64
+	entries   []uint32 // of the slots, if kept
+	mask      uint64
+	n         int
+	hasZero   bool
+	zeroEntry uint32
+}
+
+func newRobinHood(n int) *robinHood {
+	size := uint64(robinHoodMinSize)
+	for size*robinHoodMaxLoadNum/robinHoodMaxLoadDen < uint64(n) {
+		size <<= 1
+	}
+	return &robinHood{slots: make([]uint64, size), mask: size - 1}
+}
+
+// newRobinHoodEntries is a robinHood keeping an entry with each digest.
+func newRobinHoodEntries(n int) *robinHood {
+	rh := newRobinHood(n)
+	rh.entries = make([]uint32, len(rh.slots))
+	return rh
+}
+
+// dist is the distance of the digest at slot i from its home slot.
+func (rh *robinHood) dist(d, i uint64) uint64 { return (i - d) & rh.mask }
+
+func (rh *robinHood) add(d uint64) bool { _, ok := rh.put(d, 0); return ok }
+
+func (rh *robinHood) put(d uint64, e uint32) (uint32, bool) {
+	if d == 0 {
+		if rh.hasZero {
+			return rh.zeroEntry, false
+		}
+		rh.hasZero, rh.zeroEntry = true, e
+		return e, true
+	}
+	if i := rh.find(d); i >= 0 {
+		return rh.entry(uint64(i)), false
+	}
+	if uint64(rh.n+1) > uint64(len(rh.slots))*robinHoodMaxLoadNum/robinHoodMaxLoadDen {
+		rh.grow()
+	}
+	rh.insert(d, e)
+	rh.n++
+	return e, true
+}
+
+func (rh *robinHood) entry(i uint64) uint32 {
+	if rh.entries == nil {
+		return 0
+	}
+	return rh.entries[i]
+}
+
+// insert places a digest known to be absent.
+func (rh *robinHood) insert(d uint64, e uint32) {
+	i, dist := d&rh.mask, uint64(0)
+	for {
+		cur := rh.slots[i]
+		if cur == 0 {
+			rh.slots[i] = d
+			if rh.entries != nil {
+				rh.entries[i] = e
+			}
+			return
+		}
+		// the richer digest gives its slot away and moves on
+		if curDist := rh.dist(cur, i); curDist < dist {
+			rh.slots[i], d, dist = d, cur, curDist
+			if rh.entries != nil {
+				rh.entries[i], e = e, rh.entries[i]
+			}
+		}
+		i, dist = (i+1)&rh.mask, dist+1
+	}
+}
+
+func (rh *robinHood) grow() {
+	old, oldEntries := rh.slots, rh.entries
+	rh.slots = make([]uint64, 2*len(old))
+	if oldEntries != nil {
+		rh.entries = make([]uint32, len(rh.slots))
+	}
+	rh.mask = uint64(len(rh.slots)) - 1
+	for i, d := range old {
+		if d != 0 {
+			var e uint32
+			if oldEntries != nil {
+				e = oldEntries[i]
+			}
+			rh.insert(d, e)
+		}
+	}
+}
+
+// find gives the slot of a non-zero digest, or -1.
+func (rh *robinHood) find(d uint64) int {
+	i, dist := d&rh.mask, uint64(0)
+	for {
+		cur := rh.slots[i]
+		if cur == d {
+			return int(i)
+		}
+		if cur == 0 || rh.dist(cur, i) < dist {
+			return -1
+		}
+		i, dist = (i+1)&rh.mask, dist+1
+	}
+}
+
+func (rh *robinHood) has(d uint64) bool {
+	if d == 0 {
+		return rh.hasZero
+	}
+	return rh.find(d) >= 0
+}
+
+// remove a digest, shifting the digests that follow it back by one slot
+// until one is empty or at home, so no tombstone is left.
+func (rh *robinHood) remove(d uint64) { rh.take(d) }
+
+func (rh *robinHood) take(d uint64) (uint32, bool) {
+	if d == 0 {
+		had := rh.hasZero
+		rh.hasZero = false
+		return rh.zeroEntry, had
+	}
+	f := rh.find(d)
+	if f < 0 {
+		return 0, false
+	}
+	i := uint64(f)
+	e := rh.entry(i)
+	for {
+		next := (i + 1) & rh.mask
+		cur := rh.slots[next]
+		if cur == 0 || rh.dist(cur, next) == 0 {
+			break
+		}
+		rh.slots[i] = cur
+		if rh.entries != nil {
+			rh.entries[i] = rh.entries[next]
+		}
+		i = next
+	}
+	rh.slots[i] = 0
+	rh.n--
+	return e, true
+}
+
+func (rh *robinHood) len() int {
+	if rh.hasZero {
+		return rh.n + 1
+	}
+	return rh.n
+}
+
+func (rh *robinHood) each(f func(d uint64)) {
+	if rh.hasZero {
+		f(0)
+	}
+	for _, d := range rh.slots {
+		if d != 0 {
+			f(d)
+		}
+	}
+}
+
+func (rh *robinHood) fresh(n int) digestStore { return newRobinHood(n) }
+
+func (rh *robinHood) remap(remap []uint32) {
+	if rh.hasZero {
+		rh.zeroEntry = remap[rh.zeroEntry]
+	}
+	for i, d := range rh.slots {
+		if d != 0 {
+			rh.entries[i] = remap[rh.entries[i]]
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package set
+
+// Guarantees the implementation of those interfaces
+var (
+	scalableBloomIsSet           Set              = NewScalableBloom(0.01)
+	scalableBloomIsProbabilistic ProbabilisticSet = NewScalableBloom(0.01)
+)
+
+const (
+	scalableBloomInitial    = 1024 // capacity of the first filter
+	scalableBloomGrowth     = 2    // capacity ratio between two filters
+	scalableBloomTightening = 0.8  // false positive ratio between two filters
+)
+
+// ScalableBloom is a set of string implemented using a chain of Bloom
+// filters that grows as keys are added, without knowing how many will
+// be. Each filter is larger than the previous one and has a tighter
+// false positive rate, so that the false positive rate of the whole
+// chain stays below the one it was created with.
+type ScalableBloom struct {
+	filters []*Bloom
+	next    float64 // false positive rate of the next filter
+	n       int
+	fpRate  float64
+}
+
+// NewScalableBloom creates a ScalableBloom with a false positive rate of
+// at most fpRate, which must be between 0 and 1.
+func NewScalableBloom(fpRate float64) *ScalableBloom {
+	if !(fpRate > 0 && fpRate < 1) {
+		panic("set: scalable bloom false positive rate must be between 0 and 1")
+	}
+	// the rates of the filters are a geometric series summing to fpRate
+	sb := &ScalableBloom{next: fpRate * (1 - scalableBloomTightening), fpRate: fpRate}
+	sb.grow(scalableBloomInitial)
+	return sb
+}
+
+func (sb *ScalableBloom) grow(capacity int) {
+	sb.filters = append(sb.filters, NewBloom(capacity, sb.next))
+	sb.next *= scalableBloomTightening
+}
+
+// Add the key to the set.
+func (sb *ScalableBloom) Add(s string) {
+	if sb.Contains(s) {
+		return
+	}
+	last := sb.filters[len(sb.filters)-1]
+	if last.Len() >= last.Capacity() {
+		sb.grow(last.Capacity() * scalableBloomGrowth)
+		last = sb.filters[len(sb.filters)-1]
+	}
+	last.Add(s)
+	sb.n++
+}
+
+// Contains tells if this key was probably in the set at least once.
+func (sb *ScalableBloom) Contains(s string) bool {
+	for _, b := range sb.filters {
+		if b.Contains(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty tells if this set is empty.
+func (sb *ScalableBloom) IsEmpty() bool { return sb.n == 0 }
+
+// Len is the length of this set. It misses the keys that were false
+// positives when added.
+func (sb *ScalableBloom) Len() int { return sb.n }
+
+// EstimatedFalsePositiveRate is the probability that Contains is true
+// for a key that was never added, given the bits set so far.
+func (sb *ScalableBloom) EstimatedFalsePositiveRate() float64 {
+	pass := 1.0
+	for _, b := range sb.filters {
+		pass *= 1 - b.EstimatedFalsePositiveRate()
+	}
+	return 1 - pass
+}
+
+// Capacity is the number of keys the filters hold before another one is
+// added, then it grows.
+func (sb *ScalableBloom) Capacity() int {
+	capacity := 0
+	for _, b := range sb.filters {
+		capacity += b.Capacity()
+	}
+	return capacity
+}
+
+// FalsePositiveRate is the false positive rate this set was created with,
+// which it keeps as it grows.
+func (sb *ScalableBloom) FalsePositiveRate() float64 { return sb.fpRate }
+
+// IsExact is false, a ScalableBloom may claim to contain keys it never
+// saw.
+func (sb *ScalableBloom) IsExact() bool { return false }
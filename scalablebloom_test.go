@@ -0,0 +1,39 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"testing"
+)
+
+func TestScalableBloom_Empty(t *testing.T) { setTest(t, set.NewScalableBloom(0.001), []string{}) }
+func TestScalableBloom_One(t *testing.T)   { setTest(t, set.NewScalableBloom(0.001), []string{"A"}) }
+func TestScalableBloom_Many(t *testing.T) {
+	setTest(t, set.NewScalableBloom(0.001), []string{"A", "B", "C"})
+}
+func TestScalableBloom_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return set.NewScalableBloom(0.001) })
+}
+
+func TestScalableBloom_Grows(t *testing.T) {
+	const n = 20000
+	fpRate := 0.01
+	sb := set.NewScalableBloom(fpRate)
+	for i := 0; i < n; i++ {
+		sb.Add(string(rune(i)) + "in")
+	}
+	for i := 0; i < n; i++ {
+		if !sb.Contains(string(rune(i)) + "in") {
+			t.Fatalf("should contain key %d", i)
+		}
+	}
+
+	var absent []string
+	for i := 0; i < n; i++ {
+		absent = append(absent, string(rune(i))+"out")
+	}
+	fpTest(t, sb, absent, fpRate)
+
+	if got := sb.EstimatedFalsePositiveRate(); got > fpRate {
+		t.Errorf("estimated false positive rate %g, want at most %g", got, fpRate)
+	}
+}
@@ -1,5 +1,29 @@
 package set
 
+import (
+	"errors"
+)
+
+var (
+	// ErrImmutable is the reason a set built once from its keys refuses
+	// to Add new ones.
+	ErrImmutable = errors.New("set: immutable set")
+	// ErrMalformed is returned when decoding a serialized set fails.
+	ErrMalformed = errors.New("set: malformed encoding")
+	// ErrOverflow is returned when a set had to saturate a counter to
+	// add a key. The sets indexing their keys with uint32 panic with it
+	// once they run out of indexes.
+	ErrOverflow = errors.New("set: counter overflow")
+	// ErrFull is returned when a set has no room left for a key.
+	ErrFull = errors.New("set: set is full")
+	// ErrIncompatible is returned when combining sets whose layouts or
+	// hashers differ.
+	ErrIncompatible = errors.New("set: incompatible sets")
+	// ErrUnknownHasher is returned when no hasher was registered under
+	// a name.
+	ErrUnknownHasher = errors.New("set: unknown hasher")
+)
+
 // Set answers question of the type: is this string a member?
 type Set interface {
 	Add(string)
@@ -14,12 +38,45 @@ type MutableSet interface {
 	Delete(string)
 }
 
+// CheckedSet is a Set that can tell why adding a key failed or degraded
+// the set, where Add would panic or carry on silently.
+type CheckedSet interface {
+	Set
+	TryAdd(string) error
+}
+
 // ListSet is a Set that can return the its keys.
 type ListSet interface {
 	Set
 	Keys() []string
 }
 
+// PrefixSet is a ListSet that can enumerate the keys starting
+// with a prefix.
+type PrefixSet interface {
+	ListSet
+	PrefixKeys(prefix string) []string
+}
+
+// OrderedSet is a ListSet that keeps its keys sorted. Keys gives them
+// in order, Rank tells how many keys sort before a key, and Select
+// gives the key of a given rank.
+type OrderedSet interface {
+	ListSet
+	Rank(string) int
+	Select(int) (string, bool)
+}
+
+// ProbabilisticSet is a Set that may claim to contain keys it never saw.
+// FalsePositiveRate is the most likely rate of such claims while it holds
+// at most Capacity keys, and IsExact tells if it never makes any.
+type ProbabilisticSet interface {
+	Set
+	FalsePositiveRate() float64
+	Capacity() int
+	IsExact() bool
+}
+
 // Union of the two list set, the result stored in the
 // out set. Everything in A or (inclusive) B is the result.
 func Union(a, b ListSet, out Set) {
@@ -3,6 +3,7 @@ package set_test
 import (
 	"github.com/aybabtme/set"
 	"sort"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -43,17 +44,26 @@ func setTest(t *testing.T, a set.Set, want []string) {
 		t.Fatalf("should be empty")
 	}
 
+	caps := set.Capabilities(a)
+
 	for i, k := range want {
 
 		if a.Len() != i {
 			t.Fatalf("should have size %d now", i)
 		}
 
-		if a.Contains(k) {
+		// a probabilistic set may claim it already, fpTest bounds how often
+		if a.Contains(k) && !caps.Has(set.Probabilistic) {
 			t.Fatalf("should not contain %q just yet", k)
 		}
 
-		a.Add(k)
+		if caps.Has(set.Checked) {
+			if err := a.(set.CheckedSet).TryAdd(k); err != nil {
+				t.Fatalf("adding %q: %v", k, err)
+			}
+		} else {
+			a.Add(k)
+		}
 
 		if !a.Contains(k) {
 			t.Fatalf("should contain %q now", k)
@@ -69,11 +79,15 @@ func setTest(t *testing.T, a set.Set, want []string) {
 	}
 
 	notA := set.NewGoMap(setA.Len())
-	set.Difference(setA, a, notA)
-
-	for _, k := range notA.Keys() {
-		if a.Contains(k) {
-			t.Fatalf("should no contain %q", k)
+	set.Difference(setA, setFromList(want), notA)
+
+	if caps.Has(set.Probabilistic) {
+		fpTest(t, a, notA.Keys(), a.(set.ProbabilisticSet).FalsePositiveRate())
+	} else {
+		for _, k := range notA.Keys() {
+			if a.Contains(k) {
+				t.Fatalf("should no contain %q", k)
+			}
 		}
 	}
 
@@ -82,18 +96,17 @@ func setTest(t *testing.T, a set.Set, want []string) {
 	}
 
 	// list first, mutate after (mutate changes the set)
-	if listable, ok := a.(set.ListSet); ok {
-		listableTest(t, listable, want)
-	}
+	traitsTest(t, a, want)
 
-	if mutable, ok := a.(set.MutableSet); ok {
-		mutableTest(t, mutable, want)
+	if caps.Has(set.Mutable) {
+		mutableTest(t, a.(set.MutableSet), want)
 	}
 }
 
 func mutableTest(t *testing.T, a set.MutableSet, want []string) {
 	// `a` contains all of `want`
 
+	probabilistic := set.Capabilities(a).Has(set.Probabilistic)
 	for _, k := range want {
 		if !a.Contains(k) {
 			t.Fatalf("should contain %v before deletion", k)
@@ -101,10 +114,142 @@ func mutableTest(t *testing.T, a set.MutableSet, want []string) {
 
 		a.Delete(k)
 
-		if a.Contains(k) {
+		// the keys left may still make a probabilistic set claim it
+		if a.Contains(k) && !probabilistic {
 			t.Fatalf("should NOT contain %v after deletion: %#v", k, a)
 		}
 	}
+
+	if probabilistic {
+		fpTest(t, a, want, a.(set.ProbabilisticSet).FalsePositiveRate())
+	}
+}
+
+// Verifies proper implementation of a set.Set built once from a set.ListSet
+func staticTest(t *testing.T, build func(set.ListSet) set.Set, want []string) {
+	a := build(setFromList(want))
+
+	if a.IsEmpty() != (len(want) == 0) {
+		t.Fatalf("IsEmpty should be %v", len(want) == 0)
+	}
+
+	if a.Len() != len(want) {
+		t.Fatalf("should have size %d, got %d", len(want), a.Len())
+	}
+
+	for _, k := range want {
+		if !a.Contains(k) {
+			t.Fatalf("should contain %q", k)
+		}
+	}
+
+	notA := set.NewGoMap(setA.Len())
+	set.Difference(setA, setFromList(want), notA)
+
+	caps := set.Capabilities(a)
+	if caps.Has(set.Probabilistic) {
+		fpTest(t, a, notA.Keys(), a.(set.ProbabilisticSet).FalsePositiveRate())
+	} else {
+		for _, k := range notA.Keys() {
+			if a.Contains(k) {
+				t.Fatalf("should no contain %q", k)
+			}
+		}
+	}
+
+	if caps.Has(set.Checked) {
+		if err := a.(set.CheckedSet).TryAdd("A"); err != set.ErrImmutable {
+			t.Fatalf("want %v adding to a static set, got %v", set.ErrImmutable, err)
+		}
+	}
+
+	traitsTest(t, a, want)
+}
+
+// traitsTest checks the listing traits a set reports, `a` holding all of
+// `want`.
+func traitsTest(t *testing.T, a set.Set, want []string) {
+	caps := set.Capabilities(a)
+	if caps.Has(set.Listable) {
+		listableTest(t, a.(set.ListSet), append([]string(nil), want...))
+	}
+
+	if caps.Has(set.Ordered) {
+		orderedTest(t, a.(set.OrderedSet), append([]string(nil), want...))
+	}
+
+	if caps.Has(set.Prefix) {
+		prefixTest(t, a.(set.PrefixSet), "", append([]string(nil), want...))
+		if len(want) > 0 && len(want[0]) > 0 {
+			prefix := want[0][:1]
+			var withPrefix []string
+			for _, k := range want {
+				if strings.HasPrefix(k, prefix) {
+					withPrefix = append(withPrefix, k)
+				}
+			}
+			prefixTest(t, a.(set.PrefixSet), prefix, withPrefix)
+		}
+	}
+}
+
+func prefixTest(t *testing.T, a set.PrefixSet, prefix string, want []string) {
+	got := a.PrefixKeys(prefix)
+
+	if len(got) != len(want) {
+		t.Fatalf("want %d elements with prefix %q, got %d", len(want), prefix, len(got))
+	}
+
+	sort.Strings(want)
+	for i, wantk := range want {
+		if gotk := got[i]; wantk != gotk {
+			t.Errorf("index %d: want %q got %q", i, wantk, gotk)
+		}
+	}
+}
+
+func orderedTest(t *testing.T, a set.OrderedSet, want []string) {
+	// `a` contains all of `want`
+	sort.Strings(want)
+
+	got := a.Keys()
+	if !sort.StringsAreSorted(got) {
+		t.Fatalf("Keys() should be sorted")
+	}
+
+	for i, k := range want {
+		if rank := a.Rank(k); rank != i {
+			t.Fatalf("rank of %q: want %d got %d", k, i, rank)
+		}
+		if rank := a.Rank(k + "\x00"); rank != i+1 {
+			t.Fatalf("rank after %q: want %d got %d", k, i+1, rank)
+		}
+		if gotk, ok := a.Select(i); !ok || gotk != k {
+			t.Fatalf("select %d: want %q got %q", i, k, gotk)
+		}
+	}
+
+	if _, ok := a.Select(len(want)); ok {
+		t.Fatalf("select %d: should be out of range", len(want))
+	}
+	if _, ok := a.Select(-1); ok {
+		t.Fatalf("select -1: should be out of range")
+	}
+}
+
+func fpTest(t *testing.T, a set.Set, absent []string, fpRate float64) {
+	// `a` contains none of `absent`, but may claim otherwise
+	var falsePositives int
+	for _, k := range absent {
+		if a.Contains(k) {
+			falsePositives++
+		}
+	}
+
+	// leave room for the variance of small samples
+	if limit := 2*fpRate*float64(len(absent)) + 3; float64(falsePositives) > limit {
+		t.Errorf("%d false positives over %d keys, want at most %.0f", falsePositives, len(absent), limit)
+	}
 }
 
 func listableTest(t *testing.T, a set.ListSet, want []string) {
@@ -204,8 +349,7 @@ func checkOpBuilder(t *testing.T, a, b, want []string, op operation, outbuild fu
 		}
 	}
 
-	listable, ok := Out.(set.ListSet)
-	if !ok {
+	if !set.Capabilities(Out).Has(set.Listable) {
 		if once == nil {
 			once = &sync.Once{}
 		}
@@ -213,7 +357,7 @@ func checkOpBuilder(t *testing.T, a, b, want []string, op operation, outbuild fu
 		return
 	}
 
-	for _, k := range listable.Keys() {
+	for _, k := range Out.(set.ListSet).Keys() {
 		if !Want.Contains(k) {
 			t.Errorf("extra %q", k)
 		}
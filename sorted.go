@@ -0,0 +1,284 @@
+package set
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	sortedSliceIsMutable MutableSet = NewSortedSlice(0)
+	sortedSliceIsOrdered OrderedSet = NewSortedSlice(0)
+	eytzingerIsOrdered   OrderedSet = NewSortedSlice(0).Freeze()
+	eytzingerIsChecked   CheckedSet = NewSortedSlice(0).Freeze()
+)
+
+// SortedSlice is a set of string implemented using a sorted slice of strings.
+// Add appends the key, and the keys added since the last query are sorted
+// and merged in once, by the next query: building a set of n keys costs
+// O(n log n), as long as the queries wait for the keys. Deletions move the
+// keys that follow, so it is best used to build a set before freezing it
+// with Freeze.
+//
+// Unlike the other sets, the first query after an Add writes to the set,
+// so a SortedSlice is not safe for concurrent reads until a query has
+// settled the keys added last, like a call to Len.
+type SortedSlice struct {
+	keys   []string
+	sorted int // keys[:sorted] are sorted and distinct
+}
+
+// NewSortedSlice creates a SortedSlice of capacity n.
+func NewSortedSlice(n int) *SortedSlice {
+	return &SortedSlice{keys: make([]string, 0, n)}
+}
+
+// Add the key to the set.
+func (ss *SortedSlice) Add(s string) { ss.keys = append(ss.keys, s) }
+
+// settle sorts the keys added since the last query, merging them with the
+// sorted ones and dropping the duplicates.
+func (ss *SortedSlice) settle() {
+	if ss.sorted == len(ss.keys) {
+		return
+	}
+	head, tail := ss.keys[:ss.sorted], ss.keys[ss.sorted:]
+	sort.Strings(tail)
+	if len(head) == 0 {
+		ss.keys = dedupeSorted(ss.keys)
+	} else {
+		merged := make([]string, 0, len(ss.keys))
+		for len(head) != 0 && len(tail) != 0 {
+			if head[0] <= tail[0] {
+				merged, head = append(merged, head[0]), head[1:]
+			} else {
+				merged, tail = append(merged, tail[0]), tail[1:]
+			}
+		}
+		merged = append(append(merged, head...), tail...)
+		ss.keys = dedupeSorted(merged)
+	}
+	ss.sorted = len(ss.keys)
+}
+
+// dedupeSorted drops the repeated keys of a sorted slice, in place.
+func dedupeSorted(keys []string) []string {
+	if len(keys) == 0 {
+		return keys
+	}
+	out := keys[:1]
+	for _, k := range keys[1:] {
+		if k != out[len(out)-1] {
+			out = append(out, k)
+		}
+	}
+	for i := len(out); i < len(keys); i++ {
+		keys[i] = ""
+	}
+	return out
+}
+
+// Contains tells if this key was in the set at least once.
+func (ss *SortedSlice) Contains(s string) bool {
+	ss.settle()
+	i := sort.SearchStrings(ss.keys, s)
+	return i < len(ss.keys) && ss.keys[i] == s
+}
+
+// Delete the element form this set.
+func (ss *SortedSlice) Delete(s string) {
+	ss.settle()
+	i := sort.SearchStrings(ss.keys, s)
+	if i == len(ss.keys) || ss.keys[i] != s {
+		return
+	}
+	copy(ss.keys[i:], ss.keys[i+1:])
+	ss.keys[len(ss.keys)-1] = ""
+	ss.keys = ss.keys[:len(ss.keys)-1]
+	ss.sorted--
+}
+
+// IsEmpty tells if this set is empty.
+func (ss *SortedSlice) IsEmpty() bool { return len(ss.keys) == 0 }
+
+// Len is the length of this set.
+func (ss *SortedSlice) Len() int { ss.settle(); return len(ss.keys) }
+
+// Keys gives all the keys in this SortedSlice, in sorted order.
+func (ss *SortedSlice) Keys() []string {
+	ss.settle()
+	keys := make([]string, len(ss.keys))
+	copy(keys, ss.keys)
+	return keys
+}
+
+// Rank tells how many keys of the set sort before s.
+func (ss *SortedSlice) Rank(s string) int { ss.settle(); return sort.SearchStrings(ss.keys, s) }
+
+// Select gives the key of rank i, if there is one.
+func (ss *SortedSlice) Select(i int) (string, bool) {
+	ss.settle()
+	if i < 0 || i >= len(ss.keys) {
+		return "", false
+	}
+	return ss.keys[i], true
+}
+
+// Union of this set and other, merged in a single pass.
+func (ss *SortedSlice) Union(other OrderedSet) *SortedSlice {
+	return ss.merge(other, true, true, true)
+}
+
+// Intersect this set with other, merged in a single pass.
+func (ss *SortedSlice) Intersect(other OrderedSet) *SortedSlice {
+	return ss.merge(other, false, true, false)
+}
+
+// Difference of this set minus other, merged in a single pass.
+func (ss *SortedSlice) Difference(other OrderedSet) *SortedSlice {
+	return ss.merge(other, true, false, false)
+}
+
+// XOR of this set and other, merged in a single pass.
+func (ss *SortedSlice) XOR(other OrderedSet) *SortedSlice {
+	return ss.merge(other, true, false, true)
+}
+
+// merge walks both sorted sets at once, keeping the keys found only in
+// this set, in both sets, and only in other, as told.
+func (ss *SortedSlice) merge(other OrderedSet, onlyA, both, onlyB bool) *SortedSlice {
+	ss.settle()
+	a, b := ss.keys, other.Keys()
+	out := NewSortedSlice(0)
+	for len(a) != 0 && len(b) != 0 {
+		switch {
+		case a[0] < b[0]:
+			if onlyA {
+				out.keys = append(out.keys, a[0])
+			}
+			a = a[1:]
+		case a[0] > b[0]:
+			if onlyB {
+				out.keys = append(out.keys, b[0])
+			}
+			b = b[1:]
+		default:
+			if both {
+				out.keys = append(out.keys, a[0])
+			}
+			a, b = a[1:], b[1:]
+		}
+	}
+	if onlyA {
+		out.keys = append(out.keys, a...)
+	}
+	if onlyB {
+		out.keys = append(out.keys, b...)
+	}
+	out.sorted = len(out.keys)
+	return out
+}
+
+// Freeze lays the keys out in a new Eytzinger set. The SortedSlice is
+// left untouched.
+func (ss *SortedSlice) Freeze() *Eytzinger {
+	ss.settle()
+	e := &Eytzinger{
+		keys:  make([]string, len(ss.keys)+1),
+		ranks: make([]uint32, len(ss.keys)+1),
+	}
+	e.fill(ss.keys, 0, 1)
+	return e
+}
+
+// Eytzinger is a static set of string implemented using a sorted slice of
+// strings laid out in breadth-first order, like an implicit binary search
+// tree: the children of the key at k are at 2k and 2k+1. A search only
+// moves forward in memory, the top levels stay in cache and each step
+// computes the next index instead of branching on it.
+type Eytzinger struct {
+	keys  []string // 1-indexed, keys[0] is unused
+	ranks []uint32 // rank of each key in sorted order
+}
+
+// fill does an in-order walk of the tree at k, placing the sorted keys
+// from i onward. It returns the rank of the next key to place.
+func (e *Eytzinger) fill(sorted []string, i, k int) int {
+	if k >= len(e.keys) {
+		return i
+	}
+	i = e.fill(sorted, i, 2*k)
+	e.keys[k] = sorted[i]
+	e.ranks[k] = uint32(i)
+	return e.fill(sorted, i+1, 2*k+1)
+}
+
+// lowerBound finds the index of the first key not before s, or 0 if
+// every key sorts before s.
+func (e *Eytzinger) lowerBound(s string) int {
+	k := 1
+	for k < len(e.keys) {
+		k = 2*k + lessIndex(e.keys[k], s)
+	}
+	// undo the right turns taken after the last left turn
+	return k >> uint(bits.TrailingZeros(^uint(k))+1)
+}
+
+func lessIndex(a, b string) int {
+	if a < b {
+		return 1
+	}
+	return 0
+}
+
+// Add is not supported, an Eytzinger is immutable.
+func (e *Eytzinger) Add(s string) { panic(ErrImmutable) }
+
+// TryAdd always returns ErrImmutable.
+func (e *Eytzinger) TryAdd(s string) error { return ErrImmutable }
+
+// Contains tells if this key is in the set.
+func (e *Eytzinger) Contains(s string) bool {
+	k := e.lowerBound(s)
+	return k != 0 && e.keys[k] == s
+}
+
+// IsEmpty tells if this set is empty.
+func (e *Eytzinger) IsEmpty() bool { return e.Len() == 0 }
+
+// Len is the length of this set.
+func (e *Eytzinger) Len() int { return len(e.keys) - 1 }
+
+// Keys gives all the keys in this Eytzinger, in sorted order.
+func (e *Eytzinger) Keys() []string {
+	keys := make([]string, e.Len())
+	for k := 1; k < len(e.keys); k++ {
+		keys[e.ranks[k]] = e.keys[k]
+	}
+	return keys
+}
+
+// Rank tells how many keys of the set sort before s.
+func (e *Eytzinger) Rank(s string) int {
+	k := e.lowerBound(s)
+	if k == 0 {
+		return e.Len()
+	}
+	return int(e.ranks[k])
+}
+
+// Select gives the key of rank i, if there is one.
+func (e *Eytzinger) Select(i int) (string, bool) {
+	if i < 0 || i >= e.Len() {
+		return "", false
+	}
+	k := 1
+	for int(e.ranks[k]) != i {
+		if int(e.ranks[k]) < i {
+			k = 2*k + 1
+		} else {
+			k = 2 * k
+		}
+	}
+	return e.keys[k], true
+}
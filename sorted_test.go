@@ -0,0 +1,118 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"sync"
+	"testing"
+)
+
+func TestSortedSlice_Collision(t *testing.T) { collisionTest(t, set.NewSortedSlice(0)) }
+func TestSortedSlice_Empty(t *testing.T)     { setTest(t, set.NewSortedSlice(0), []string{}) }
+func TestSortedSlice_One(t *testing.T)       { setTest(t, set.NewSortedSlice(0), []string{"A"}) }
+func TestSortedSlice_Many(t *testing.T)      { setTest(t, set.NewSortedSlice(0), []string{"A", "B", "C"}) }
+func TestSortedSlice_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return set.NewSortedSlice(0) })
+}
+
+func TestSortedSlice_Ordered(t *testing.T) {
+	want := setA.Keys()
+	ss := set.NewSortedSlice(len(want))
+	for _, k := range want {
+		ss.Add(k)
+	}
+	orderedTest(t, ss, want)
+}
+
+func TestSortedSlice_Builder(t *testing.T) {
+	// keys added out of order and twice, with queries in between, are
+	// merged in sorted and once
+	ss := set.NewSortedSlice(0)
+	for _, k := range []string{"d", "b", "d", "a"} {
+		ss.Add(k)
+	}
+	if !ss.Contains("b") || ss.Len() != 3 {
+		t.Fatalf("want 3 keys with %q, got %d", "b", ss.Len())
+	}
+	for _, k := range []string{"c", "a", "e", "c"} {
+		ss.Add(k)
+	}
+	ss.Delete("d")
+	orderedTest(t, ss, []string{"a", "b", "c", "e"})
+}
+
+func TestSortedSlice_ConcurrentContains(t *testing.T) {
+	ss := set.NewSortedSlice(0)
+	for _, k := range web2 {
+		ss.Add(k)
+	}
+	// settle the keys before sharing the set
+	ss.Len()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, k := range web2 {
+				if !ss.Contains(k) {
+					t.Errorf("should contain %q", k)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSortedSlice_Merge(t *testing.T) {
+	merges := []struct {
+		name string
+		op   func(a *set.SortedSlice, b set.OrderedSet) *set.SortedSlice
+		want operation
+	}{
+		{"Union", (*set.SortedSlice).Union, set.Union},
+		{"Intersect", (*set.SortedSlice).Intersect, relax(set.Intersect)},
+		{"Difference", (*set.SortedSlice).Difference, relax(set.Difference)},
+		{"XOR", (*set.SortedSlice).XOR, set.XOR},
+	}
+	for _, merge := range merges {
+		t.Logf("== %s ==", merge.name)
+		a, b := sortedFromList(web2[:len(web2)/2]), sortedFromList(web2[len(web2)/4:])
+		want := set.NewGoMap(0)
+		merge.want(a, b, want)
+
+		// merging against a frozen set works the same
+		for _, other := range []set.OrderedSet{b, b.Freeze()} {
+			got := merge.op(a, other)
+			orderedTest(t, got, want.Keys())
+			listableTest(t, got, want.Keys())
+		}
+	}
+}
+
+func sortedFromList(words []string) *set.SortedSlice {
+	ss := set.NewSortedSlice(len(words))
+	for _, word := range words {
+		ss.Add(word)
+	}
+	return ss
+}
+
+func buildEytzinger(s set.ListSet) set.Set {
+	ss := set.NewSortedSlice(s.Len())
+	for _, k := range s.Keys() {
+		ss.Add(k)
+	}
+	return ss.Freeze()
+}
+
+func TestEytzinger_Empty(t *testing.T) { staticTest(t, buildEytzinger, []string{}) }
+func TestEytzinger_One(t *testing.T)   { staticTest(t, buildEytzinger, []string{"A"}) }
+func TestEytzinger_Many(t *testing.T)  { staticTest(t, buildEytzinger, []string{"A", "B", "C"}) }
+func TestEytzinger_Words(t *testing.T) { staticTest(t, buildEytzinger, setA.Keys()) }
+
+func TestEytzinger_Ordered(t *testing.T) {
+	for n := 0; n < 40; n++ {
+		want := setA.Keys()[:n]
+		orderedTest(t, buildEytzinger(setFromList(want)).(set.OrderedSet), want)
+	}
+}
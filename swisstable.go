@@ -0,0 +1,209 @@
+package set
+
+import (
+	"github.com/dgryski/go-farm"
+	"math/bits"
+)
+
+// Guarantees the implementation of those interfaces
+var (
+	swissTableIsMutable MutableSet = NewSwissTable(0)
+	swissTableIsList    ListSet    = NewSwissTable(0)
+)
+
+const (
+	swissGroupSize = 8
+
+	// control bytes, a full slot has the 7 low bits of its key's hash
+	swissEmpty   = 0x80
+	swissDeleted = 0xfe
+
+	swissLSB = 0x0101010101010101
+	swissMSB = 0x8080808080808080
+)
+
+// SwissTable is a set of string implemented using a SwissTable: an open
+// addressing table probed by groups of 8 slots, each with a control byte
+// holding 7 bits of its key's hash. The control bytes of a group are read
+// as a single word and matched all at once, so most lookups compare a
+// single key. Keys are stored back to back in a chunked arena, each slot
+// holding only where its key is.
+type SwissTable struct {
+	ctrl  []uint64 // the 8 control bytes of each group
+	slots []swissSlot
+	arena chunkArena
+	mask  uint64 // groups - 1
+
+	n       int
+	deleted int // slots marked deleted
+}
+
+// swissSlot is where a key is in the arena.
+type swissSlot struct{ chunk, pos, len uint32 }
+
+// NewSwissTable creates a SwissTable of capacity n.
+func NewSwissTable(n int) *SwissTable {
+	groups := uint64(1)
+	for groups*swissGroupSize*7/8 < uint64(n) {
+		groups <<= 1
+	}
+	st := &SwissTable{}
+	st.reset(groups)
+	return st
+}
+
+func (st *SwissTable) reset(groups uint64) {
+	st.ctrl = make([]uint64, groups)
+	for i := range st.ctrl {
+		st.ctrl[i] = swissLSB * swissEmpty
+	}
+	st.slots = make([]swissSlot, groups*swissGroupSize)
+	st.mask = groups - 1
+	st.n, st.deleted = 0, 0
+}
+
+func swissHash(s string) (h1 uint64, h2 uint8) {
+	h := farm.Hash64([]byte(s))
+	return h >> 7, uint8(h & 0x7f)
+}
+
+// matchByte gives the high bit of each byte of w equal to b. It may give
+// a false match for a byte following a true match, which comparing keys
+// weeds out.
+func matchByte(w uint64, b uint8) uint64 {
+	x := w ^ (swissLSB * uint64(b))
+	return (x - swissLSB) &^ x & swissMSB
+}
+
+func matchEmpty(w uint64) uint64 { return w &^ (w << 6) & swissMSB }
+
+func matchEmptyOrDeleted(w uint64) uint64 { return w & swissMSB }
+
+func (st *SwissTable) setCtrl(i uint64, c uint8) {
+	shift := i % swissGroupSize * 8
+	w := &st.ctrl[i/swissGroupSize]
+	*w = *w&^(0xff<<shift) | uint64(c)<<shift
+}
+
+func (st *SwissTable) bytes(sl swissSlot) []byte { return st.arena.bytes(sl.chunk, sl.pos, sl.len) }
+
+func (st *SwissTable) key(i uint64) string { return string(st.bytes(st.slots[i])) }
+
+func (st *SwissTable) equal(i uint64, s string) bool {
+	sl := st.slots[i]
+	return int(sl.len) == len(s) && string(st.bytes(sl)) == s
+}
+
+// find gives the slot of the key, or -1. Groups are probed at
+// triangular offsets, which visits all of them.
+func (st *SwissTable) find(s string, h1 uint64, h2 uint8) int {
+	g := h1 & st.mask
+	for step := uint64(1); ; step++ {
+		w := st.ctrl[g]
+		for m := matchByte(w, h2); m != 0; m &= m - 1 {
+			i := g*swissGroupSize + uint64(bits.TrailingZeros64(m)/8)
+			if st.equal(i, s) {
+				return int(i)
+			}
+		}
+		if matchEmpty(w) != 0 {
+			return -1
+		}
+		g = (g + step) & st.mask
+	}
+}
+
+// insert places the key of slot sl, known to be absent, in the first
+// slot free on its probe sequence.
+func (st *SwissTable) insert(h1 uint64, h2 uint8, sl swissSlot) {
+	g := h1 & st.mask
+	for step := uint64(1); ; step++ {
+		if m := matchEmptyOrDeleted(st.ctrl[g]); m != 0 {
+			i := g*swissGroupSize + uint64(bits.TrailingZeros64(m)/8)
+			if uint8(st.ctrl[g]>>(i%swissGroupSize*8)) == swissDeleted {
+				st.deleted--
+			}
+			st.setCtrl(i, h2)
+			st.slots[i] = sl
+			st.n++
+			return
+		}
+		g = (g + step) & st.mask
+	}
+}
+
+// rehash moves the keys to a table of the given groups, dropping the
+// deleted slots and the garbage of the arena.
+func (st *SwissTable) rehash(groups uint64) {
+	oldCtrl, oldSlots, oldArena := st.ctrl, st.slots, st.arena
+	st.reset(groups)
+	st.arena = chunkArena{}
+	for g, w := range oldCtrl {
+		for m := matchEmptyOrDeleted(w) ^ swissMSB; m != 0; m &= m - 1 {
+			sl := oldSlots[g*swissGroupSize+bits.TrailingZeros64(m)/8]
+			h := farm.Hash64(oldArena.bytes(sl.chunk, sl.pos, sl.len))
+			chunk, pos := st.arena.move(&oldArena, sl.chunk, sl.pos, sl.len)
+			st.insert(h>>7, uint8(h&0x7f), swissSlot{chunk, pos, sl.len})
+		}
+	}
+}
+
+// Add the key to the set.
+func (st *SwissTable) Add(s string) {
+	h1, h2 := swissHash(s)
+	if st.find(s, h1, h2) >= 0 {
+		return
+	}
+	if capacity := len(st.slots) * 7 / 8; st.n+st.deleted >= capacity {
+		groups := uint64(len(st.ctrl))
+		if st.n >= capacity/2 {
+			groups *= 2
+		}
+		st.rehash(groups)
+	}
+	chunk, pos := st.arena.add(s)
+	st.insert(h1, h2, swissSlot{chunk, pos, uint32(len(s))})
+}
+
+// Contains tells if this key is in the set.
+func (st *SwissTable) Contains(s string) bool {
+	h1, h2 := swissHash(s)
+	return st.find(s, h1, h2) >= 0
+}
+
+// Delete the element form this set.
+func (st *SwissTable) Delete(s string) {
+	h1, h2 := swissHash(s)
+	f := st.find(s, h1, h2)
+	if f < 0 {
+		return
+	}
+	i := uint64(f)
+	// a group that still has an empty slot never let a probe go past it,
+	// so the slot can be empty again rather than deleted
+	if matchEmpty(st.ctrl[i/swissGroupSize]) != 0 {
+		st.setCtrl(i, swissEmpty)
+	} else {
+		st.setCtrl(i, swissDeleted)
+		st.deleted++
+	}
+	st.slots[i] = swissSlot{}
+	st.n--
+}
+
+// IsEmpty tells if this set is empty.
+func (st *SwissTable) IsEmpty() bool { return st.n == 0 }
+
+// Len is the length of this set.
+func (st *SwissTable) Len() int { return st.n }
+
+// Keys gives all the keys in this SwissTable, in no particular order.
+func (st *SwissTable) Keys() []string {
+	keys := make([]string, 0, st.n)
+	for g, w := range st.ctrl {
+		for m := matchEmptyOrDeleted(w) ^ swissMSB; m != 0; m &= m - 1 {
+			keys = append(keys, st.key(uint64(g*swissGroupSize+bits.TrailingZeros64(m)/8)))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,42 @@
+package set_test
+
+import (
+	"github.com/aybabtme/set"
+	"strconv"
+	"testing"
+)
+
+func TestSwissTable_Empty(t *testing.T) { setTest(t, set.NewSwissTable(0), []string{}) }
+func TestSwissTable_One(t *testing.T)   { setTest(t, set.NewSwissTable(0), []string{"A"}) }
+func TestSwissTable_Many(t *testing.T)  { setTest(t, set.NewSwissTable(0), []string{"A", "B", "C"}) }
+func TestSwissTable_Operations(t *testing.T) {
+	checkSetOp(t, func() set.Set { return set.NewSwissTable(0) })
+}
+
+func TestSwissTable_100Many(t *testing.T) {
+	setTest(t, set.NewSwissTable(100), []string{"A", "B", "C"})
+}
+
+func TestSwissTable_Web2(t *testing.T) { setTest(t, set.NewSwissTable(0), web2) }
+
+func TestSwissTable_Churn(t *testing.T) {
+	// deleting and adding back fills the table with deleted slots, which
+	// rehashing must reclaim
+	st := set.NewSwissTable(0)
+	for round := 0; round < 50; round++ {
+		for i := 0; i < 100; i++ {
+			st.Add(strconv.Itoa(round*100 + i))
+		}
+		for i := 0; i < 100; i++ {
+			st.Delete(strconv.Itoa(round*100 + i))
+		}
+	}
+	if !st.IsEmpty() {
+		t.Fatalf("should be empty, has %d keys", st.Len())
+	}
+	for i := 0; i < 5000; i++ {
+		if st.Contains(strconv.Itoa(i)) {
+			t.Fatalf("should not contain %d", i)
+		}
+	}
+}